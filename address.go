@@ -0,0 +1,64 @@
+package cloudant
+
+import (
+	"net/url"
+	"strings"
+)
+
+// designPrefix is the path prefix of design documents
+const designPrefix = "_design/"
+
+// structuralPrefixes are the ID prefixes the server expects as a separate
+// path segment rather than escaped as part of the ID.
+var structuralPrefixes = []string{designPrefix, localPrefix}
+
+// documentPath returns the escaped URL path of a document relative to its
+// database. The ID is escaped as a single path segment, so that IDs holding
+// '/', '?', '#', '%' or dot segments address the right document, except for
+// the "_design/" and "_local/" prefixes which are kept as they are.
+func documentPath(documentID string) string {
+	for _, prefix := range structuralPrefixes {
+		if strings.HasPrefix(documentID, prefix) && len(documentID) > len(prefix) {
+			return prefix + escapePathSegment(documentID[len(prefix):])
+		}
+	}
+	return escapePathSegment(documentID)
+}
+
+// attachmentPath returns the escaped URL path of an attachment relative to
+// its database.
+func attachmentPath(documentID, name string) string {
+	return documentPath(documentID) + "/" + escapePathSegment(name)
+}
+
+// viewPath returns the escaped URL path of a view relative to its database.
+func viewPath(designDocID, view string) string {
+	if !strings.HasPrefix(designDocID, designPrefix) {
+		designDocID = designPrefix + designDocID
+	}
+	return documentPath(designDocID) + "/_view/" + escapePathSegment(view)
+}
+
+// escapePathSegment escapes s to be used as a single URL path segment. '+'
+// is escaped too as some servers decode it as a space, and segments made of
+// dots are escaped so that they aren't resolved as relative paths.
+func escapePathSegment(s string) string {
+	if strings.Trim(s, ".") == "" {
+		return strings.Repeat("%2E", len(s))
+	}
+	return strings.ReplaceAll(url.PathEscape(s), "+", "%2B")
+}
+
+// documentURL returns the URL of a path escaped by documentPath,
+// attachmentPath or viewPath. Unlike Endpoint it keeps the escaping intact.
+func documentURL(base url.URL, escapedPath string, params url.Values) (string, error) {
+	rawPath := strings.TrimSuffix(base.EscapedPath(), "/") + "/" + escapedPath
+	unescaped, err := url.PathUnescape(rawPath)
+	if err != nil {
+		return "", err
+	}
+
+	base.Path, base.RawPath = unescaped, rawPath
+	base.RawQuery = params.Encode()
+	return base.String(), nil
+}
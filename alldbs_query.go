@@ -23,6 +23,7 @@ type AllDBsQueryBuilder interface {
 	EndKey(string) AllDBsQueryBuilder
 	InclusiveEnd() AllDBsQueryBuilder
 	Limit(int) AllDBsQueryBuilder
+	Prefix(string) AllDBsQueryBuilder
 	Skip(int) AllDBsQueryBuilder
 	StartKey(string) AllDBsQueryBuilder
 	Build() *allDBsQuery
@@ -65,6 +66,14 @@ func (a *allDBsQueryBuilder) Limit(lim int) AllDBsQueryBuilder {
 	return a
 }
 
+// Prefix restricts the results to database names starting with prefix by
+// setting both the start and end keys.
+func (a *allDBsQueryBuilder) Prefix(prefix string) AllDBsQueryBuilder {
+	a.startKey = fmt.Sprintf("\"%s\"", prefix)
+	a.endKey = fmt.Sprintf("\"%s\ufff0\"", prefix)
+	return a
+}
+
 func (a *allDBsQueryBuilder) Skip(skip int) AllDBsQueryBuilder {
 	a.skip = skip
 	return a
@@ -37,3 +37,18 @@ func TestAllDBsQuery_Args(t *testing.T) {
 		}
 	}
 }
+
+func TestAllDBsQuery_Prefix(t *testing.T) {
+	query := NewAllDBsQuery().
+		Prefix("tenant-").
+		Build()
+
+	values, _ := query.GetQuery()
+
+	if values.Get("startkey") != `"tenant-"` {
+		t.Errorf("unexpected startkey %s", values.Get("startkey"))
+	}
+	if values.Get("endkey") != "\"tenant-\ufff0\"" {
+		t.Errorf("unexpected endkey %s", values.Get("endkey"))
+	}
+}
@@ -0,0 +1,143 @@
+package cloudant
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// AttachmentReader streams the content of an attachment. Always call Close()
+// to release the underlying connection.
+type AttachmentReader struct {
+	io.ReadCloser
+	ContentType   string
+	ContentLength int64  // -1 if unknown
+	Digest        string // e.g. "md5-aEI7pOYCRBLTRQvvqYrrJQ==", as in a document's _attachments
+	ContentRange  string // set if a Range() was requested and honoured
+}
+
+// PutAttachment uploads an attachment to a document, streaming the content
+// from r. Use an empty rev to create a new document holding only the attachment.
+// If r is a *bytes.Reader, *bytes.Buffer or *strings.Reader its length is
+// checked against the database's request limit before uploading; the length
+// of other readers is unknown until sent, so they are not checked.
+// See: https://docs.couchdb.org/en/stable/api/document/attachments.html#put--db-docid-attname
+func (d *Database) PutAttachment(documentID, rev, name, contentType string, r io.Reader) (*DocumentMeta, error) {
+	if err := d.checkRequestSize(documentID, readerLength(r)); err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	if rev != "" {
+		query.Add("rev", rev)
+	}
+	urlStr, err := documentURL(*d.URL, attachmentPath(documentID, name), query)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := http.Header{}
+	headers.Set("Content-Type", contentType)
+
+	job, err := d.client.streamRequest("PUT", urlStr, r, headers)
+	defer job.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	err = expectedReturnCodes(job, 201, 202)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &DocumentMeta{}
+	err = json.NewDecoder(job.response.Body).Decode(resp)
+
+	return resp, err
+}
+
+// GetAttachment returns a reader streaming the content of an attachment.
+// See: https://docs.couchdb.org/en/stable/api/document/attachments.html#get--db-docid-attname
+func (d *Database) GetAttachment(documentID, name string, args *attachmentQuery) (*AttachmentReader, error) {
+	params, err := args.GetQuery()
+	if err != nil {
+		return nil, err
+	}
+	urlStr, err := documentURL(*d.URL, attachmentPath(documentID, name), params)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := http.Header{}
+	if args.Range != "" {
+		headers.Set("Range", args.Range)
+	}
+
+	job, err := d.client.requestWithHeaders("GET", urlStr, nil, headers)
+	if err != nil {
+		if job != nil {
+			job.Close()
+		}
+		return nil, err
+	}
+
+	err = expectedReturnCodes(job, 200, 206)
+	if err != nil {
+		job.Close()
+		return nil, err
+	}
+
+	// the body is handed to the caller as-is; closing it early must not
+	// drain the rest of a large download as job.Close() would
+	resp := job.response
+	return &AttachmentReader{
+		ReadCloser:    resp.Body,
+		ContentType:   resp.Header.Get("Content-Type"),
+		ContentLength: resp.ContentLength,
+		Digest:        attachmentDigest(resp),
+		ContentRange:  resp.Header.Get("Content-Range"),
+	}, nil
+}
+
+// DeleteAttachment removes an attachment from a document, returning the
+// document's new revision.
+// See: https://docs.couchdb.org/en/stable/api/document/attachments.html#delete--db-docid-attname
+func (d *Database) DeleteAttachment(documentID, rev, name string) (*DocumentMeta, error) {
+	query := url.Values{}
+	query.Add("rev", rev)
+	urlStr, err := documentURL(*d.URL, attachmentPath(documentID, name), query)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := d.client.request("DELETE", urlStr, nil)
+	defer job.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	err = expectedReturnCodes(job, 200, 202)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &DocumentMeta{}
+	err = json.NewDecoder(job.response.Body).Decode(resp)
+
+	return resp, err
+}
+
+// attachmentDigest returns the digest of an attachment response in the
+// format used by a document's _attachments.
+func attachmentDigest(response *http.Response) string {
+	if md5 := response.Header.Get("Content-MD5"); md5 != "" {
+		return "md5-" + md5
+	}
+	etag := strings.Trim(response.Header.Get("ETag"), "\"")
+	if etag != "" && !strings.HasPrefix(etag, "md5-") {
+		etag = "md5-" + etag
+	}
+	return etag
+}
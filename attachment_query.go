@@ -0,0 +1,74 @@
+package cloudant
+
+// QueryBuilder implementation for the GetAttachment() API call.
+//
+// Example:
+// 	query := cloudant.NewAttachmentQuery().
+//     Range(0, 1023).
+//     Build()
+//
+//	att, err := db.GetAttachment(docID, "photo.jpg", query)
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// AttachmentQueryBuilder defines the available parameter-setting functions.
+type AttachmentQueryBuilder interface {
+	Range(int64, int64) AttachmentQueryBuilder
+	Rev(string) AttachmentQueryBuilder
+	Build() *attachmentQuery
+}
+
+type attachmentQueryBuilder struct {
+	rangeHeader string
+	rev         string
+}
+
+// attachmentQuery holds the implemented API call parameters. Range is sent
+// as a header rather than in the query string.
+type attachmentQuery struct {
+	Range string
+	Rev   string
+}
+
+// NewAttachmentQuery is the entry point.
+func NewAttachmentQuery() AttachmentQueryBuilder {
+	return &attachmentQueryBuilder{}
+}
+
+// Range requests the bytes from start to end inclusive. Use an end < 0 to read
+// to the end of the attachment.
+func (a *attachmentQueryBuilder) Range(start, end int64) AttachmentQueryBuilder {
+	if end < 0 {
+		a.rangeHeader = fmt.Sprintf("bytes=%d-", start)
+	} else {
+		a.rangeHeader = fmt.Sprintf("bytes=%d-%d", start, end)
+	}
+	return a
+}
+
+func (a *attachmentQueryBuilder) Rev(rev string) AttachmentQueryBuilder {
+	a.rev = rev
+	return a
+}
+
+// GetQuery implements the QueryBuilder interface. It returns an
+// url.Values map with the non-default values set.
+func (aq *attachmentQuery) GetQuery() (url.Values, error) {
+	vals := url.Values{}
+
+	if aq.Rev != "" {
+		vals.Set("rev", aq.Rev)
+	}
+
+	return vals, nil
+}
+
+func (a *attachmentQueryBuilder) Build() *attachmentQuery {
+	return &attachmentQuery{
+		Range: a.rangeHeader,
+		Rev:   a.rev,
+	}
+}
@@ -0,0 +1,28 @@
+package cloudant
+
+import (
+	"testing"
+)
+
+func TestAttachmentQuery_Args(t *testing.T) {
+	// Range string
+	// Rev   string
+
+	query := NewAttachmentQuery().
+		Range(100, 199).
+		Rev("1-bf1b7e045f2843995184f78022b3d0f5").
+		Build()
+
+	values, _ := query.GetQuery()
+	if values.Encode() != "rev=1-bf1b7e045f2843995184f78022b3d0f5" {
+		t.Errorf("unexpected query string '%s'", values.Encode())
+	}
+	if query.Range != "bytes=100-199" {
+		t.Errorf("unexpected range '%s'", query.Range)
+	}
+
+	query = NewAttachmentQuery().Range(100, -1).Build()
+	if query.Range != "bytes=100-" {
+		t.Errorf("unexpected open-ended range '%s'", query.Range)
+	}
+}
@@ -0,0 +1,65 @@
+package cloudant
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDatabase_Attachments(t *testing.T) {
+	database, err := makeDatabase()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer func() {
+		fmt.Printf("Deleting database %s", database.Name)
+		database.client.Delete(database.Name)
+	}()
+
+	content := []byte(strings.Repeat("0123456789", 1000))
+
+	meta, err := database.PutAttachment("doc-att", "", "data.txt", "text/plain", bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("failed to upload attachment: %s", err)
+	}
+	if !strings.HasPrefix(meta.Rev, "1-") {
+		t.Errorf("unexpected revision %s", meta.Rev)
+	}
+
+	// Note: lame attempt to close inconsistency window
+	time.Sleep(500 * time.Millisecond)
+
+	att, err := database.GetAttachment("doc-att", "data.txt", NewAttachmentQuery().Build())
+	if err != nil {
+		t.Fatalf("failed to download attachment: %s", err)
+	}
+	data, err := ioutil.ReadAll(att)
+	att.Close()
+	if err != nil || !bytes.Equal(data, content) {
+		t.Errorf("unexpected attachment content (%d bytes, %v)", len(data), err)
+	}
+	if !strings.HasPrefix(att.ContentType, "text/plain") || !strings.HasPrefix(att.Digest, "md5-") {
+		t.Errorf("unexpected attachment metadata %+v", att)
+	}
+
+	att, err = database.GetAttachment("doc-att", "data.txt", NewAttachmentQuery().Range(10, 19).Build())
+	if err != nil {
+		t.Fatalf("failed to download attachment range: %s", err)
+	}
+	data, _ = ioutil.ReadAll(att)
+	att.Close()
+	if string(data) != "0123456789" {
+		t.Errorf("unexpected attachment range %q", data)
+	}
+
+	meta, err = database.DeleteAttachment("doc-att", meta.Rev, "data.txt")
+	if err != nil {
+		t.Fatalf("failed to delete attachment: %s", err)
+	}
+	if !strings.HasPrefix(meta.Rev, "2-") {
+		t.Errorf("unexpected revision %s", meta.Rev)
+	}
+}
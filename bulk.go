@@ -32,8 +32,11 @@ type BulkJobI interface {
 }
 
 // BulkJob represents the state of a single document to be uploaded as part of a batch
+// Once uploaded, the '_id' and '_rev' assigned by the server are written back
+// into the document if it is a pointer to a struct or a map.
 type BulkJob struct {
 	doc      interface{}
+	body     interface{} // doc, or a copy of it holding a generated ID
 	Error    error
 	isDone   chan bool
 	priority bool
@@ -43,6 +46,7 @@ type BulkJob struct {
 func newBulkJob(doc interface{}, priority bool) *BulkJob {
 	return &BulkJob{
 		doc:      doc,
+		body:     doc,
 		Error:    nil,
 		isDone:   make(chan bool, 1),
 		priority: priority,
@@ -231,16 +235,13 @@ func (u *Uploader) Stop() {
 
 // FireAndForget adds a document to the upload queue ready for processing by the upload worker(s).
 func (u *Uploader) FireAndForget(doc interface{}) {
-	u.uploadChan <- newBulkJob(doc, false)
+	u.Upload(doc)
 }
 
 // Upload adds a document to the upload queue ready for processing by the upload worker(s). A
 // BulkJob type is returned to the client so that progress can be monitored.
 func (u *Uploader) Upload(doc interface{}) *BulkJob {
-	job := newBulkJob(doc, false)
-	u.uploadChan <- job
-
-	return job
+	return u.enqueue(doc, false)
 }
 
 // UploadNow adds a priority document to the upload queue ready for processing by the upload
@@ -248,12 +249,38 @@ func (u *Uploader) Upload(doc interface{}) *BulkJob {
 // the current batch size). A BulkJob type is returned to the client so that progress can be
 // monitored.
 func (u *Uploader) UploadNow(doc interface{}) *BulkJob {
-	job := newBulkJob(doc, true)
+	return u.enqueue(doc, true)
+}
+
+// enqueue assigns an ID to the document if the database has an IDGenerator,
+// and adds it to the upload queue. IDs are assigned here rather than by the
+// workers, as they may be written into the caller's document.
+func (u *Uploader) enqueue(doc interface{}, priority bool) *BulkJob {
+	job := newBulkJob(doc, priority)
+
+	body, err := u.database.withGeneratedID(doc)
+	if err != nil {
+		job.Error = err
+		job.done()
+		return job
+	}
+
+	job.body = body
 	u.uploadChan <- job
 
 	return job
 }
 
+// maxBatchBytes is the size limit of a _bulk_docs request: the smaller of
+// batchMaxBytes and the database's request limit, or 0 if there is none.
+func (u *Uploader) maxBatchBytes() int {
+	maxBytes := u.batchMaxBytes
+	if limit := u.database.limits().MaxRequestBytes; limit > 0 && (maxBytes <= 0 || limit < maxBytes) {
+		maxBytes = limit
+	}
+	return maxBytes
+}
+
 type bulkWorker struct {
 	id       int
 	jobChan  chan BulkJobI
@@ -295,14 +322,26 @@ func (w *bulkWorker) start() {
 
 			switch j := job.(type) {
 			case *BulkJob:
-				jsonDocBytes, err := json.Marshal(j.doc)
+				jsonDocBytes, err := w.uploader.database.encodeBody(j.doc, j.body, nil)
 				if err != nil {
 					j.Error = fmt.Errorf("invalid JSON - %s", err)
 					j.done()
 					break
 				}
 
-				if len(liveJobs) >= w.uploader.batchSize || (w.uploader.batchMaxBytes > 0 && len(bulkDocsBytes)+len(jsonDocBytes) > w.uploader.batchMaxBytes-2) {
+				if err = w.uploader.database.checkDocumentSize(jsonDocBytes); err != nil {
+					j.Error = err
+					j.done()
+					break
+				}
+
+				// the batch must also fit the ',' separator, if any, and the closing ']}'
+				maxBytes := w.uploader.maxBatchBytes()
+				closing := 2
+				if len(liveJobs) > 0 {
+					closing++
+				}
+				if len(liveJobs) >= w.uploader.batchSize || (maxBytes > 0 && len(bulkDocsBytes)+len(jsonDocBytes) > maxBytes-closing) {
 					processJobs(w.uploader.NewEdits, nil, &liveJobs, &bulkDocsBytes, w.uploader)
 				}
 
@@ -359,8 +398,12 @@ func processJobs(isNewEdits bool, parent BulkJobI, jobs *[]*BulkJob, bulkDocsByt
 
 	*bulkDocsBytes = append(*bulkDocsBytes, 93, 125) // add ']}'
 
-	if uploader.batchMaxBytes > 0 && len(*bulkDocsBytes) > uploader.batchMaxBytes {
-		errorAllJobs(jobs, "payload too large")
+	if maxBytes := uploader.maxBatchBytes(); maxBytes > 0 && len(*bulkDocsBytes) > maxBytes {
+		err := &ErrDocumentTooLarge{Size: int64(len(*bulkDocsBytes)), Limit: int64(maxBytes)}
+		for _, j := range *jobs {
+			j.Error = err
+		}
+		doneAllJobs(jobs)
 	} else {
 		b := bytes.NewReader(*bulkDocsBytes)
 		result, err := uploader.database.client.request("POST", uploader.database.URL.String()+"/_bulk_docs", b)
@@ -424,6 +467,8 @@ func processResult(jobs *[]*BulkJob, result *Job, err error, isNewEdits bool) {
 			job.Response = &responses[i]
 			if job.Response.Error != "" {
 				job.Error = fmt.Errorf("%s - %s", job.Response.Error, job.Response.Reason)
+			} else {
+				SetDocumentMeta(job.doc, &DocumentMeta{ID: job.Response.ID, Rev: job.Response.Rev})
 			}
 		}
 	}
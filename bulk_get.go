@@ -0,0 +1,291 @@
+package cloudant
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+)
+
+var bulkGetChunkSize = 500 // max. documents per /_bulk_get request
+
+// BulkGetRequest identifies a document to fetch with BulkGet. If Rev is empty
+// the winning revision is returned.
+type BulkGetRequest struct {
+	ID        string   `json:"id"`
+	Rev       string   `json:"rev,omitempty"`
+	AttsSince []string `json:"atts_since,omitempty"`
+}
+
+// BulkGetResult is a document returned by BulkGet. Either Doc or Error is set.
+type BulkGetResult struct {
+	ID    string
+	Rev   string
+	Doc   json.RawMessage
+	Error *CouchError
+
+	db *Database
+}
+
+// bulkGetResponse is the response from the _bulk_get endpoint
+type bulkGetResponse struct {
+	Results []struct {
+		ID   string       `json:"id"`
+		Docs []bulkGetDoc `json:"docs"`
+	} `json:"results"`
+}
+
+// bulkGetDoc is a document, or an error, in a _bulk_get response
+type bulkGetDoc struct {
+	OK    json.RawMessage `json:"ok,omitempty"`
+	Error *bulkGetError   `json:"error,omitempty"`
+}
+
+type bulkGetError struct {
+	ID     string `json:"id"`
+	Rev    string `json:"rev"`
+	Error  string `json:"error"`
+	Reason string `json:"reason"`
+}
+
+// allDocsKeysResponse is the response of a POST to _all_docs with keys
+type allDocsKeysResponse struct {
+	Rows []struct {
+		Key   string          `json:"key"`
+		Error string          `json:"error,omitempty"`
+		Doc   json.RawMessage `json:"doc"`
+		Value struct {
+			Rev     string `json:"rev"`
+			Deleted bool   `json:"deleted"`
+		} `json:"value"`
+	} `json:"rows"`
+}
+
+// Decode unmarshals the document into target, decrypting its fields if the
+// database is configured to.
+func (r *BulkGetResult) Decode(target interface{}) error {
+	if r.Error != nil {
+		return r.Error
+	}
+	if r.db == nil {
+		return json.Unmarshal(r.Doc, target)
+	}
+	return r.db.decodeJSON(r.Doc, target)
+}
+
+// BulkGet fetches many documents, at specific revisions if given, in as few
+// requests as possible. Large requests are split into chunks. On servers
+// without _bulk_get the documents are fetched with _all_docs if possible, or
+// else one at a time.
+// See: https://docs.couchdb.org/en/stable/api/database/bulk-api.html#db-bulk-get
+func (d *Database) BulkGet(requests []BulkGetRequest, args *bulkGetQuery) ([]*BulkGetResult, error) {
+	results := []*BulkGetResult{}
+	supported := true
+
+	for start := 0; start < len(requests); start += bulkGetChunkSize {
+		end := start + bulkGetChunkSize
+		if end > len(requests) {
+			end = len(requests)
+		}
+		chunk := requests[start:end]
+
+		var chunkResults []*BulkGetResult
+		var err error
+		if supported {
+			chunkResults, err = d.bulkGet(chunk, args)
+			if dbErr, ok := err.(*CouchError); ok && bulkGetUnsupported(dbErr) {
+				supported = false
+			}
+		}
+		if !supported {
+			chunkResults, err = d.bulkGetFallback(chunk, args)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, result := range chunkResults {
+			result.db = d
+		}
+		results = append(results, chunkResults...)
+	}
+
+	return results, nil
+}
+
+func (d *Database) bulkGet(requests []BulkGetRequest, args *bulkGetQuery) ([]*BulkGetResult, error) {
+	params, err := args.GetQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(map[string][]BulkGetRequest{"docs": requests})
+	if err != nil {
+		return nil, err
+	}
+
+	urlStr, err := Endpoint(*d.URL, "/_bulk_get", params)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := d.client.request("POST", urlStr, bytes.NewReader(body))
+	defer job.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	err = expectedReturnCodes(job, 200)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &bulkGetResponse{}
+	err = json.NewDecoder(job.response.Body).Decode(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*BulkGetResult{}
+	for _, result := range resp.Results {
+		for _, doc := range result.Docs {
+			if doc.Error != nil {
+				results = append(results, &BulkGetResult{
+					ID:    result.ID,
+					Rev:   doc.Error.Rev,
+					Error: bulkGetCouchError(doc.Error.Error, doc.Error.Reason),
+				})
+				continue
+			}
+
+			meta := &openRevsDoc{}
+			if err = json.Unmarshal(doc.OK, meta); err != nil {
+				return nil, err
+			}
+			results = append(results, &BulkGetResult{ID: result.ID, Rev: meta.Rev, Doc: doc.OK})
+		}
+	}
+
+	return results, nil
+}
+
+// bulkGetFallback fetches documents on servers without _bulk_get
+func (d *Database) bulkGetFallback(requests []BulkGetRequest, args *bulkGetQuery) ([]*BulkGetResult, error) {
+	if !args.Revs && !hasRevisions(requests) {
+		return d.bulkGetAllDocs(requests)
+	}
+
+	results := make([]*BulkGetResult, 0, len(requests))
+	for _, request := range requests {
+		query := &getQuery{
+			AttsSince: request.AttsSince,
+			Latest:    args.Latest,
+			Rev:       request.Rev,
+			Revs:      args.Revs,
+		}
+
+		// the document is decrypted by BulkGetResult.Decode
+		doc, err := d.getRaw(request.ID, query)
+		if dbErr, ok := err.(*CouchError); ok {
+			results = append(results, &BulkGetResult{ID: request.ID, Rev: request.Rev, Error: dbErr})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		meta := &openRevsDoc{}
+		if err = json.Unmarshal(doc, meta); err != nil {
+			return nil, err
+		}
+		results = append(results, &BulkGetResult{ID: request.ID, Rev: meta.Rev, Doc: doc})
+	}
+
+	return results, nil
+}
+
+func (d *Database) bulkGetAllDocs(requests []BulkGetRequest) ([]*BulkGetResult, error) {
+	keys := make([]string, len(requests))
+	for i, request := range requests {
+		keys[i] = request.ID
+	}
+
+	body, err := json.Marshal(map[string][]string{"keys": keys})
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("include_docs", "true")
+
+	urlStr, err := Endpoint(*d.URL, "/_all_docs", params)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := d.client.request("POST", urlStr, bytes.NewReader(body))
+	defer job.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	err = expectedReturnCodes(job, 200)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &allDocsKeysResponse{}
+	err = json.NewDecoder(job.response.Body).Decode(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*BulkGetResult, 0, len(resp.Rows))
+	for _, row := range resp.Rows {
+		switch {
+		case row.Error != "":
+			results = append(results, &BulkGetResult{ID: row.Key, Error: bulkGetCouchError(row.Error, "missing")})
+		case row.Value.Deleted:
+			results = append(results, &BulkGetResult{ID: row.Key, Rev: row.Value.Rev, Error: bulkGetCouchError("not_found", "deleted")})
+		default:
+			results = append(results, &BulkGetResult{ID: row.Key, Rev: row.Value.Rev, Doc: row.Doc})
+		}
+	}
+
+	return results, nil
+}
+
+func hasRevisions(requests []BulkGetRequest) bool {
+	for _, request := range requests {
+		if request.Rev != "" || len(request.AttsSince) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// bulkGetUnsupported returns true if the error means the server has no
+// _bulk_get endpoint (e.g. CouchDB 1.6). Other errors, such as a 400 for a
+// malformed request, are returned to the caller.
+func bulkGetUnsupported(err *CouchError) bool {
+	switch err.StatusCode {
+	case 404, 405, 501:
+		return true
+	default:
+		return false
+	}
+}
+
+func bulkGetCouchError(errStr, reason string) *CouchError {
+	statusCode := 500
+	switch errStr {
+	case "not_found":
+		statusCode = 404
+	case "bad_request":
+		statusCode = 400
+	case "unauthorized":
+		statusCode = 401
+	case "forbidden":
+		statusCode = 403
+	}
+	return &CouchError{Err: errStr, Reason: reason, StatusCode: statusCode}
+}
@@ -0,0 +1,31 @@
+package cloudant
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBulkGetQuery_Args(t *testing.T) {
+	// Latest bool
+	// Revs   bool
+
+	expectedQueryStrings := []string{
+		"latest=true",
+		"revs=true",
+	}
+
+	query := NewBulkGetQuery().
+		Latest().
+		Revs().
+		Build()
+
+	values, _ := query.GetQuery()
+	queryString := values.Encode()
+
+	for _, str := range expectedQueryStrings {
+		if !strings.Contains(queryString, str) {
+			t.Errorf("parameter encoding not found '%s' in '%s'", str, queryString)
+			return
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package cloudant
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBulkGet_CouchError(t *testing.T) {
+	err := bulkGetCouchError("not_found", "missing")
+	if err.StatusCode != 404 || err.Err != "not_found" || err.Reason != "missing" {
+		t.Errorf("unexpected error %+v", err)
+	}
+}
+
+func TestBulkGet_Unsupported(t *testing.T) {
+	expected := map[int]bool{400: false, 404: true, 405: true, 500: false, 501: true}
+	for statusCode, unsupported := range expected {
+		if bulkGetUnsupported(&CouchError{StatusCode: statusCode}) != unsupported {
+			t.Errorf("%d: expected unsupported %v", statusCode, unsupported)
+		}
+	}
+}
+
+// TestBulkGet_FallbackDecryptsOnce checks that documents fetched one at a
+// time are decrypted by Decode only, using a value that looks encrypted once
+// decrypted.
+func TestBulkGet_FallbackDecryptsOnce(t *testing.T) {
+	encryption := testEncryption()
+	keyID, key, _ := encryption.Keys.CurrentKey()
+	inner, _ := encryption.encryptValue("ssn", "123-45-6789", keyID, key, false)
+	outer, _ := encryption.encryptValue("ssn", inner, keyID, key, false)
+
+	client, server := makeMockClient(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/_bulk_get") {
+			w.WriteHeader(404)
+			w.Write([]byte(`{"error":"not_found","reason":"missing"}`))
+			return
+		}
+		fmt.Fprintf(w, `{"_id":"doc","_rev":"1-a","ssn":%q}`, outer)
+	})
+	defer server.Close()
+
+	database, err := client.Get("db")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	database.Encryption = encryption
+
+	results, err := database.BulkGet([]BulkGetRequest{{ID: "doc", Rev: "1-a"}}, NewBulkGetQuery().Build())
+	if err != nil || len(results) != 1 {
+		t.Fatalf("unexpected results %+v (%v)", results, err)
+	}
+	doc := &encryptedDocument{}
+	if err = results[0].Decode(doc); err != nil || doc.SSN != inner {
+		t.Errorf("expected a single decryption, found %+v (%v)", doc, err)
+	}
+}
+
+func TestDatabase_BulkGet(t *testing.T) {
+	database, err := makeDatabase()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer func() {
+		fmt.Printf("Deleting database %s", database.Name)
+		database.client.Delete(database.Name)
+	}()
+
+	meta, err := database.Set(&cloudantDocument{ID: "doc-bulk-get", Foo: "mydata", Bar: 57})
+	if err != nil {
+		t.Fatalf("failed to create document: %s", err)
+	}
+
+	// Note: lame attempt to close inconsistency window
+	time.Sleep(500 * time.Millisecond)
+
+	requests := []BulkGetRequest{
+		{ID: "doc-bulk-get", Rev: meta.Rev},
+		{ID: "doc-missing"},
+	}
+	results, err := database.BulkGet(requests, NewBulkGetQuery().Revs().Build())
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("unexpected number of results %d", len(results))
+	}
+
+	doc := &cloudantDocument{}
+	err = results[0].Decode(doc)
+	if err != nil || results[0].Rev != meta.Rev || doc.Foo != "mydata" {
+		t.Errorf("unexpected result %+v (%v)", results[0], err)
+	}
+	if results[1].Error == nil || results[1].Error.StatusCode != 404 {
+		t.Errorf("expected not_found for missing document, got %+v", results[1])
+	}
+}
@@ -39,6 +39,7 @@ type CouchClient struct {
 	workers       []*worker
 	workerChan    chan chan *Job
 	workerCount   int
+	uuids         uuidCache
 }
 
 // QueryBuilder is used by functions implementing Cloudant API calls
@@ -267,17 +268,83 @@ func (c *CouchClient) LogOut() {
 }
 
 func (c *CouchClient) request(method, path string, body io.Reader) (job *Job, err error) {
-	req, err := http.NewRequest(method, path, body)
+	return c.requestWithHeaders(method, path, body, nil)
+}
+
+// requestWithHeaders is like request, but adds the given headers. Any
+// Content-Type header given replaces the default application/json.
+func (c *CouchClient) requestWithHeaders(method, path string, body io.Reader, headers http.Header) (job *Job, err error) {
+	req, err := newRequest(method, path, body, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.execute(CreateJob(req))
+}
+
+// streamRequest is like requestWithHeaders, but the body is streamed to the
+// server rather than read into memory first. Such requests are only retried if
+// the body can be rewound (e.g. a *bytes.Reader).
+func (c *CouchClient) streamRequest(method, path string, body io.Reader, headers http.Header) (job *Job, err error) {
+	req, err := newRequest(method, path, body, headers)
 	if err != nil {
 		return nil, err
 	}
 
-	if req.Method == "POST" {
-		req.Header.Add("Content-Type", "application/json") // add Content-Type for POSTs
+	job = CreateJob(req)
+	job.streamBody = true
+
+	return c.execute(job)
+}
+
+// probeRequest is like request, but the first response or error is returned
+// without retrying, so that health checks report the server's current state.
+func (c *CouchClient) probeRequest(method, path string) (job *Job, err error) {
+	req, err := newRequest(method, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	job = CreateJob(req)
+	job.noRetry = true
+
+	return c.execute(job)
+}
+
+// adminRequest is like request, but the session is renewed at most once: a
+// 401 or 403 response that persists means the user isn't a server admin.
+func (c *CouchClient) adminRequest(method, path string, body io.Reader) (job *Job, err error) {
+	req, err := newRequest(method, path, body, nil)
+	if err != nil {
+		return nil, err
 	}
 
 	job = CreateJob(req)
+	job.renewOnce = true
+
+	return c.execute(job)
+}
+
+func newRequest(method, path string, body io.Reader, headers http.Header) (*http.Request, error) {
+	req, err := http.NewRequest(method, path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	if req.Header.Get("Content-Type") == "" && (req.Method == "POST" || (req.Method == "PUT" && body != nil)) {
+		req.Header.Add("Content-Type", "application/json") // add Content-Type for POSTs & PUTs
+	}
+
+	return req, nil
+}
 
+func (c *CouchClient) execute(job *Job) (*Job, error) {
 	c.Execute(job)
 	job.Wait()
 
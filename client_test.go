@@ -2,11 +2,35 @@ package cloudant
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"strings"
 	"testing"
 )
 
+// makeMockClient returns a client of a local server answering with handler,
+// for testing responses a real server can't easily be made to give. Failed
+// requests are retried up to 3 times without delay.
+func makeMockClient(handler http.HandlerFunc) (*CouchClient, *httptest.Server) {
+	server := httptest.NewServer(handler)
+	rootURL, _ := url.Parse(server.URL)
+
+	client := &CouchClient{
+		rootURL:       rootURL,
+		httpClient:    server.Client(),
+		jobQueue:      make(chan *Job, 100),
+		workerCount:   2,
+		retryCountMax: 3,
+		retryDelayMin: 0,
+		retryDelayMax: 1,
+	}
+	startDispatcher(client)
+
+	return client, server
+}
+
 func TestInvalidLogin(t *testing.T) {
 	username := os.Getenv("COUCH_USER")
 	password := "wR0ng_pa$$w0rd"
@@ -143,3 +167,77 @@ func TestAllDBs_Limit(t *testing.T) {
 		t.Errorf("expected %d databases, found %d", limit, len(*dbList))
 	}
 }
+
+func TestAllDBsIterator(t *testing.T) {
+	if travis() {
+		fmt.Printf("[SKIP] TestAllDBsIterator requires CouchDB 2.X")
+		return
+	}
+	dbNames := map[string]bool{}
+	client, err := makeClient()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	for i := 0; i < 25; i++ {
+		dbname := fmt.Sprintf("cccc%d", i)
+		_, err = client.GetOrCreate(dbname)
+		if err != nil {
+			t.Fatalf("%s", err)
+		}
+		dbNames[dbname] = true
+	}
+	defer func() {
+		for name := range dbNames {
+			client.Delete(name)
+		}
+	}()
+
+	query := NewAllDBsQuery().Prefix("cccc").Build()
+	it := client.AllDBsIterator(query, 7) // forces several pages
+
+	found := 0
+	for it.Next() {
+		if !dbNames[it.Name()] {
+			t.Errorf("unexpected database name: %s", it.Name())
+		}
+		found++
+	}
+	if it.Err() != nil {
+		t.Fatalf("%s", it.Err())
+	}
+	if found != len(dbNames) {
+		t.Errorf("expected %d databases, found %d", len(dbNames), found)
+	}
+}
+
+func TestDBsInfo(t *testing.T) {
+	if travis() {
+		fmt.Printf("[SKIP] TestDBsInfo requires CouchDB 2.X")
+		return
+	}
+	database, err := makeDatabase()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer func() {
+		fmt.Printf("Deleting database %s", database.Name)
+		database.client.Delete(database.Name)
+	}()
+
+	makeDocuments(database, 10)
+
+	results, err := database.client.DBsInfo([]string{database.Name, "golang-missing"})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, found %d", len(results))
+	}
+	if results[0].Info == nil || results[0].Info.DocCount != 10 {
+		t.Errorf("unexpected info for %s: %+v", database.Name, results[0].Info)
+	}
+	if results[1].Error == "" {
+		t.Error("expected error for missing database")
+	}
+}
@@ -0,0 +1,275 @@
+package cloudant
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// UpStatus is the response from the /_up endpoint
+type UpStatus struct {
+	Status     string `json:"status"`
+	StatusCode int    `json:"-"`
+}
+
+// OK returns true if the node is up and accepting requests.
+func (u *UpStatus) OK() bool {
+	return u.StatusCode == 200 && u.Status == "ok"
+}
+
+// Membership is the response from the /_membership endpoint.
+// ClusterNodes are the nodes configured to be in the cluster, AllNodes are
+// the nodes the responding node is currently connected to.
+type Membership struct {
+	AllNodes     []string `json:"all_nodes"`
+	ClusterNodes []string `json:"cluster_nodes"`
+}
+
+// Missing returns the configured cluster nodes that are not currently connected.
+func (m *Membership) Missing() []string {
+	connected := map[string]bool{}
+	for _, node := range m.AllNodes {
+		connected[node] = true
+	}
+
+	missing := []string{}
+	for _, node := range m.ClusterNodes {
+		if !connected[node] {
+			missing = append(missing, node)
+		}
+	}
+	return missing
+}
+
+// NodeStats is the tree of metrics returned by /_node/{node}/_stats, keyed by
+// subsystem (e.g. "couchdb") and then by metric name.
+type NodeStats map[string]json.RawMessage
+
+// NodeStat is a single metric from /_node/{node}/_stats
+type NodeStat struct {
+	Type  string          `json:"type"` // counter, gauge or histogram
+	Desc  string          `json:"desc"`
+	Value json.RawMessage `json:"value"`
+}
+
+// NodeHistogram is the value of a histogram metric
+type NodeHistogram struct {
+	Min               float64     `json:"min"`
+	Max               float64     `json:"max"`
+	ArithmeticMean    float64     `json:"arithmetic_mean"`
+	GeometricMean     float64     `json:"geometric_mean"`
+	HarmonicMean      float64     `json:"harmonic_mean"`
+	Median            float64     `json:"median"`
+	Variance          float64     `json:"variance"`
+	StandardDeviation float64     `json:"standard_deviation"`
+	Skewness          float64     `json:"skewness"`
+	Kurtosis          float64     `json:"kurtosis"`
+	Percentile        [][]float64 `json:"percentile"`
+	Histogram         [][]float64 `json:"histogram"`
+	N                 int         `json:"n"`
+}
+
+// Stat looks up a metric by its path, e.g. Stat("couchdb", "request_time").
+func (s NodeStats) Stat(path ...string) (*NodeStat, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("empty stat path")
+	}
+
+	raw, ok := s[path[0]]
+	if !ok {
+		return nil, fmt.Errorf("stat not found: %s", path[0])
+	}
+
+	for i := 1; i < len(path); i++ {
+		group := map[string]json.RawMessage{}
+		if err := json.Unmarshal(raw, &group); err != nil {
+			return nil, err
+		}
+		if raw, ok = group[path[i]]; !ok {
+			return nil, fmt.Errorf("stat not found: %v", path[:i+1])
+		}
+	}
+
+	stat := &NodeStat{}
+	if err := json.Unmarshal(raw, stat); err != nil {
+		return nil, err
+	}
+	if stat.Type == "" {
+		return nil, fmt.Errorf("not a metric: %v", path)
+	}
+
+	return stat, nil
+}
+
+// Number returns the value of a counter or gauge metric.
+func (s *NodeStat) Number() (float64, error) {
+	var value float64
+	err := json.Unmarshal(s.Value, &value)
+	return value, err
+}
+
+// Histogram returns the value of a histogram metric.
+func (s *NodeStat) Histogram() (*NodeHistogram, error) {
+	if s.Type != "histogram" {
+		return nil, fmt.Errorf("not a histogram: %s", s.Type)
+	}
+	value := &NodeHistogram{}
+	err := json.Unmarshal(s.Value, value)
+	return value, err
+}
+
+// NodeMemory is the Erlang VM memory usage reported by /_node/{node}/_system
+type NodeMemory struct {
+	Other         int64 `json:"other"`
+	Atom          int64 `json:"atom"`
+	AtomUsed      int64 `json:"atom_used"`
+	Processes     int64 `json:"processes"`
+	ProcessesUsed int64 `json:"processes_used"`
+	Binary        int64 `json:"binary"`
+	Code          int64 `json:"code"`
+	ETS           int64 `json:"ets"`
+}
+
+// NodeSystem is the response from the /_node/{node}/_system endpoint
+type NodeSystem struct {
+	Uptime                  int64                      `json:"uptime"`
+	Memory                  NodeMemory                 `json:"memory"`
+	RunQueue                int                        `json:"run_queue"`
+	ETSTableCount           int                        `json:"ets_table_count"`
+	ContextSwitches         int64                      `json:"context_switches"`
+	Reductions              int64                      `json:"reductions"`
+	GarbageCollectionCount  int64                      `json:"garbage_collection_count"`
+	WordsReclaimed          int64                      `json:"words_reclaimed"`
+	IOInput                 int64                      `json:"io_input"`
+	IOOutput                int64                      `json:"io_output"`
+	OSProcCount             int                        `json:"os_proc_count"`
+	StaleProcCount          int                        `json:"stale_proc_count"`
+	ProcessCount            int                        `json:"process_count"`
+	ProcessLimit            int                        `json:"process_limit"`
+	InternalReplicationJobs int                        `json:"internal_replication_jobs"`
+	MessageQueues           map[string]json.RawMessage `json:"message_queues"`
+	Distribution            map[string]json.RawMessage `json:"distribution"`
+}
+
+// ShardsInfo is the response from the /{db}/_shards endpoint. Shards maps
+// each shard range to the nodes holding a copy of it.
+type ShardsInfo struct {
+	Shards map[string][]string `json:"shards"`
+}
+
+// Up checks whether the server is up and not in maintenance mode.
+// A node in maintenance mode is not an error; check UpStatus.OK(). The
+// request is not retried, so the result reflects the server's current state.
+// See: https://docs.couchdb.org/en/stable/api/server/common.html#up
+func (c *CouchClient) Up() (*UpStatus, error) {
+	urlStr, err := Endpoint(*c.rootURL, "/_up", url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := c.probeRequest("GET", urlStr)
+	if err != nil {
+		if job != nil {
+			job.Close()
+		}
+		return nil, err
+	}
+	defer job.Close()
+
+	err = expectedReturnCodes(job, 200, 404, 503)
+	if err != nil {
+		return nil, err
+	}
+
+	up := &UpStatus{}
+	err = json.NewDecoder(job.response.Body).Decode(up)
+	up.StatusCode = job.response.StatusCode
+
+	return up, err
+}
+
+// Membership returns the nodes in the cluster.
+// See: https://docs.couchdb.org/en/stable/api/server/common.html#membership
+func (c *CouchClient) Membership() (*Membership, error) {
+	membership := &Membership{}
+	err := c.getJSON("/_membership", url.Values{}, membership)
+	if err != nil {
+		return nil, err
+	}
+	return membership, nil
+}
+
+// NodeStats returns the metrics of a node. Use "_local" for the node
+// handling the request.
+// See: https://docs.couchdb.org/en/stable/api/server/common.html#node-node-name-stats
+func (c *CouchClient) NodeStats(node string) (NodeStats, error) {
+	stats := NodeStats{}
+	err := c.getJSON("/_node/"+node+"/_stats", url.Values{}, &stats)
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// NodeSystem returns the Erlang VM statistics of a node. Use "_local" for
+// the node handling the request.
+// See: https://docs.couchdb.org/en/stable/api/server/common.html#node-node-name-system
+func (c *CouchClient) NodeSystem(node string) (*NodeSystem, error) {
+	system := &NodeSystem{}
+	err := c.getJSON("/_node/"+node+"/_system", url.Values{}, system)
+	if err != nil {
+		return nil, err
+	}
+	return system, nil
+}
+
+// Shards returns the shard ranges of the database and the nodes holding them.
+// See: https://docs.couchdb.org/en/stable/api/database/shard.html
+func (d *Database) Shards() (*ShardsInfo, error) {
+	urlStr, err := Endpoint(*d.URL, "/_shards", url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := d.client.request("GET", urlStr, nil)
+	defer job.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	err = expectedReturnCodes(job, 200)
+	if err != nil {
+		return nil, err
+	}
+
+	shards := &ShardsInfo{}
+	err = json.NewDecoder(job.response.Body).Decode(shards)
+
+	return shards, err
+}
+
+// getJSON decodes the JSON response of a GET request relative to the root URL.
+func (c *CouchClient) getJSON(pathStr string, params url.Values, target interface{}) error {
+	urlStr, err := Endpoint(*c.rootURL, pathStr, params)
+	if err != nil {
+		return err
+	}
+
+	return c.getURLJSON(urlStr, target)
+}
+
+// getURLJSON is getJSON for a URL already built, e.g. by documentURL.
+func (c *CouchClient) getURLJSON(urlStr string, target interface{}) error {
+	job, err := c.request("GET", urlStr, nil)
+	defer job.Close()
+	if err != nil {
+		return err
+	}
+
+	err = expectedReturnCodes(job, 200)
+	if err != nil {
+		return err
+	}
+
+	return json.NewDecoder(job.response.Body).Decode(target)
+}
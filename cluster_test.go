@@ -0,0 +1,127 @@
+package cloudant
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestClient_Up(t *testing.T) {
+	if travis() {
+		fmt.Printf("[SKIP] TestClient_Up requires CouchDB 2.X")
+		return
+	}
+	client, err := makeClient()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	up, err := client.Up()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if !up.OK() {
+		t.Errorf("unexpected up status %s (%d)", up.Status, up.StatusCode)
+	}
+}
+
+func TestDatabase_Shards(t *testing.T) {
+	if travis() {
+		fmt.Printf("[SKIP] TestDatabase_Shards requires CouchDB 2.X")
+		return
+	}
+	database, err := makeDatabase()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer func() {
+		fmt.Printf("Deleting database %s", database.Name)
+		database.client.Delete(database.Name)
+	}()
+
+	info, err := database.Shards()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(info.Shards) == 0 {
+		t.Error("expected at least one shard range")
+	}
+}
+
+func TestMembership_Missing(t *testing.T) {
+	membership := &Membership{
+		AllNodes:     []string{"node1@a", "node2@a"},
+		ClusterNodes: []string{"node1@a", "node2@a", "node3@a"},
+	}
+
+	missing := membership.Missing()
+	if len(missing) != 1 || missing[0] != "node3@a" {
+		t.Errorf("unexpected missing nodes %v", missing)
+	}
+}
+
+func TestNodeStats_Stat(t *testing.T) {
+	data := []byte(`{
+		"couchdb": {
+			"open_databases": {"value": 12, "type": "counter", "desc": "number of open databases"},
+			"request_time": {"value": {"min": 1.5, "max": 20, "median": 3, "n": 7}, "type": "histogram", "desc": "length of a request"},
+			"httpd_request_methods": {
+				"GET": {"value": 42, "type": "counter", "desc": "number of HTTP GET requests"}
+			}
+		}
+	}`)
+
+	stats := NodeStats{}
+	if err := json.Unmarshal(data, &stats); err != nil {
+		t.Fatal(err)
+	}
+
+	stat, err := stats.Stat("couchdb", "httpd_request_methods", "GET")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := stat.Number(); value != 42 {
+		t.Errorf("unexpected GET count %v", value)
+	}
+
+	stat, err = stats.Stat("couchdb", "request_time")
+	if err != nil {
+		t.Fatal(err)
+	}
+	histogram, err := stat.Histogram()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if histogram.Max != 20 || histogram.N != 7 {
+		t.Errorf("unexpected histogram %+v", histogram)
+	}
+
+	if _, err = stats.Stat("couchdb", "httpd_request_methods"); err == nil {
+		t.Error("expected error for a group of metrics")
+	}
+	if _, err = stats.Stat("couchdb", "nope"); err == nil {
+		t.Error("expected error for a missing metric")
+	}
+}
+
+func TestClient_UpMaintenanceMode(t *testing.T) {
+	requests := 0
+	client, server := makeMockClient(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(503)
+		w.Write([]byte(`{"status":"maintenance_mode"}`))
+	})
+	defer server.Close()
+
+	up, err := client.Up()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if up.OK() || up.Status != "maintenance_mode" || up.StatusCode != 503 {
+		t.Errorf("unexpected up status %s (%d)", up.Status, up.StatusCode)
+	}
+	if requests != 1 {
+		t.Errorf("expected a single request, found %d", requests)
+	}
+}
@@ -0,0 +1,150 @@
+package cloudant
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// NotAdminError is returned by calls that require server admin privileges
+// when the client's user is not a server admin, i.e. when the request is
+// still rejected with a 401 or 403 after renewing the session once.
+type NotAdminError struct {
+	StatusCode int
+	Path       string
+}
+
+// Error() implements the error interface
+func (e *NotAdminError) Error() string {
+	return fmt.Sprintf("%d: server admin privileges required for %s", e.StatusCode, e.Path)
+}
+
+// RevsLimit returns the maximum number of revisions the database tracks per
+// document.
+// See: https://docs.couchdb.org/en/stable/api/database/misc.html#db-revs-limit
+func (d *Database) RevsLimit() (int, error) {
+	return d.getLimit("/_revs_limit")
+}
+
+// SetRevsLimit sets the maximum number of revisions the database tracks per
+// document.
+func (d *Database) SetRevsLimit(limit int) error {
+	return d.setLimit("/_revs_limit", limit)
+}
+
+// Config returns the value of a configuration key of a node. Use "_local" for
+// the node handling the request. Not available on Cloudant.
+// See: https://docs.couchdb.org/en/stable/api/server/configuration.html
+func (c *CouchClient) Config(node, section, key string) (string, error) {
+	var value string
+	err := c.config("GET", node, section, key, nil, &value)
+	return value, err
+}
+
+// ConfigSection returns all the keys of a configuration section of a node.
+func (c *CouchClient) ConfigSection(node, section string) (map[string]string, error) {
+	values := map[string]string{}
+	err := c.config("GET", node, section, "", nil, &values)
+	return values, err
+}
+
+// SetConfig updates the value of a configuration key of a node, returning the
+// previous value.
+func (c *CouchClient) SetConfig(node, section, key, value string) (string, error) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	var oldValue string
+	err = c.config("PUT", node, section, key, body, &oldValue)
+	return oldValue, err
+}
+
+// DeleteConfig removes a configuration key of a node, returning the previous
+// value.
+func (c *CouchClient) DeleteConfig(node, section, key string) (string, error) {
+	var oldValue string
+	err := c.config("DELETE", node, section, key, nil, &oldValue)
+	return oldValue, err
+}
+
+func (c *CouchClient) config(method, node, section, key string, body []byte, target interface{}) error {
+	pathStr := "/_node/" + node + "/_config/" + section
+	if key != "" {
+		pathStr += "/" + key
+	}
+
+	urlStr, err := Endpoint(*c.rootURL, pathStr, url.Values{})
+	if err != nil {
+		return err
+	}
+
+	job, err := c.adminRequest(method, urlStr, bytes.NewReader(body))
+	if err != nil {
+		if job != nil {
+			job.Close()
+		}
+		return err
+	}
+	defer job.Close()
+
+	if job.response.StatusCode == 401 || job.response.StatusCode == 403 {
+		return &NotAdminError{StatusCode: job.response.StatusCode, Path: pathStr}
+	}
+
+	err = expectedReturnCodes(job, 200)
+	if err != nil {
+		return err
+	}
+
+	return json.NewDecoder(job.response.Body).Decode(target)
+}
+
+// getLimit reads an integer database setting such as _revs_limit.
+func (d *Database) getLimit(pathStr string) (int, error) {
+	urlStr, err := Endpoint(*d.URL, pathStr, url.Values{})
+	if err != nil {
+		return 0, err
+	}
+
+	job, err := d.client.request("GET", urlStr, nil)
+	defer job.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	err = expectedReturnCodes(job, 200)
+	if err != nil {
+		return 0, err
+	}
+
+	var limit int
+	err = json.NewDecoder(job.response.Body).Decode(&limit)
+
+	return limit, err
+}
+
+// setLimit writes an integer database setting such as _revs_limit.
+func (d *Database) setLimit(pathStr string, limit int) error {
+	if limit <= 0 {
+		return fmt.Errorf("limit must be >= 1")
+	}
+
+	urlStr, err := Endpoint(*d.URL, pathStr, url.Values{})
+	if err != nil {
+		return err
+	}
+
+	body := strings.NewReader(strconv.Itoa(limit))
+	job, err := d.client.request("PUT", urlStr, body)
+	defer job.Close()
+	if err != nil {
+		return err
+	}
+
+	return expectedReturnCodes(job, 200)
+}
@@ -0,0 +1,127 @@
+package cloudant
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestDatabase_RevsLimit(t *testing.T) {
+	database, err := makeDatabase()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer func() {
+		fmt.Printf("Deleting database %s", database.Name)
+		database.client.Delete(database.Name)
+	}()
+
+	err = database.SetRevsLimit(50)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	limit, err := database.RevsLimit()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if limit != 50 {
+		t.Errorf("unexpected revs limit %d", limit)
+	}
+
+	if err = database.SetRevsLimit(0); err == nil {
+		t.Error("expected error for invalid revs limit")
+	}
+}
+
+func TestClient_Config(t *testing.T) {
+	if travis() {
+		fmt.Printf("[SKIP] TestClient_Config requires CouchDB 2.X")
+		return
+	}
+	client, err := makeClient()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	_, err = client.SetConfig("_local", "go_cloudant", "test_key", "value1")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer client.DeleteConfig("_local", "go_cloudant", "test_key")
+
+	old, err := client.SetConfig("_local", "go_cloudant", "test_key", "value2")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if old != "value1" {
+		t.Errorf("unexpected previous value %s", old)
+	}
+
+	value, err := client.Config("_local", "go_cloudant", "test_key")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if value != "value2" {
+		t.Errorf("unexpected value %s", value)
+	}
+
+	section, err := client.ConfigSection("_local", "go_cloudant")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if section["test_key"] != "value2" {
+		t.Errorf("unexpected section %v", section)
+	}
+}
+
+func TestClient_ConfigNotAdmin(t *testing.T) {
+	var mutex sync.Mutex
+	requests := map[string]int{}
+	client, server := makeMockClient(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requests[r.URL.Path]++
+		mutex.Unlock()
+		w.WriteHeader(401)
+		w.Write([]byte(`{"error":"unauthorized","reason":"You are not a server admin."}`))
+	})
+	defer server.Close()
+
+	_, err := client.Config("_local", "couchdb", "max_document_size")
+	notAdmin, ok := err.(*NotAdminError)
+	if !ok || notAdmin.StatusCode != 401 || notAdmin.Path != "/_node/_local/_config/couchdb/max_document_size" {
+		t.Fatalf("expected *NotAdminError, found %v", err)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(requests) != 2 || requests[notAdmin.Path] != 2 || requests["/_session"] != 1 {
+		t.Errorf("expected a single retry after renewing the session, found %v", requests)
+	}
+}
+
+func TestClient_ConfigExpiredSession(t *testing.T) {
+	var mutex sync.Mutex
+	loggedIn := false
+	client, server := makeMockClient(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		switch {
+		case r.URL.Path == "/_session":
+			loggedIn = true
+			w.Write([]byte(`{"ok":true}`))
+		case !loggedIn:
+			w.WriteHeader(401)
+			w.Write([]byte(`{"error":"unauthorized","reason":"Session expired."}`))
+		default:
+			w.Write([]byte(`"1048576"`))
+		}
+	})
+	defer server.Close()
+
+	value, err := client.Config("_local", "couchdb", "max_document_size")
+	if err != nil || value != "1048576" {
+		t.Errorf("expected the request to succeed after renewing the session, found %q (%v)", value, err)
+	}
+}
@@ -0,0 +1,302 @@
+package cloudant
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ConflictsDesignDoc is the design document holding the view used by
+// ConflictResolver to find conflicted documents.
+const ConflictsDesignDoc = "_design/cloudant-conflicts"
+
+const conflictsView = "conflicts"
+
+const conflictsMapFunction = "function(doc) { if (doc._conflicts) { emit(doc._id, doc._conflicts); } }"
+
+// MergeFunc merges the live leaf revisions of a conflicted document into the
+// document body to keep. Revisions are ordered with the current winner first
+// and include their revision history. Returning a nil document skips the
+// document and leaves its conflicts in place.
+type MergeFunc func(documentID string, revisions []*OpenRevision) (interface{}, error)
+
+// ConflictResolution is the outcome of resolving the conflicts of a document
+type ConflictResolution struct {
+	ID      string
+	Rev     string   // revision of the merged document
+	Deleted []string // losing revisions that were deleted
+	Skipped bool     // true if there was nothing to resolve or the merge returned nil
+	Error   error
+}
+
+// ConflictResolver finds conflicted documents and resolves them with a
+// user-provided merge function. The merged document is written on top of the
+// winning revision and the losing revisions are deleted, all in a single
+// _bulk_docs request.
+type ConflictResolver struct {
+	db        *Database
+	merge     MergeFunc
+	BatchSize int // number of conflicted documents fetched per page by ResolveAll
+}
+
+// conflictsViewRow is a row of the conflicts view
+type conflictsViewRow struct {
+	ID    string   `json:"id"`
+	Value []string `json:"value"`
+}
+
+// conflictsViewResponse is the response of the conflicts view
+type conflictsViewResponse struct {
+	Rows []conflictsViewRow `json:"rows"`
+}
+
+// NewConflictResolver creates a ConflictResolver on database using merge to
+// decide the content of each resolved document.
+func NewConflictResolver(database *Database, merge MergeFunc) *ConflictResolver {
+	return &ConflictResolver{
+		db:        database,
+		merge:     merge,
+		BatchSize: 100,
+	}
+}
+
+// EnsureView creates the design document of the view listing conflicted
+// documents, if it doesn't exist already.
+func (r *ConflictResolver) EnsureView() error {
+	designDoc := map[string]interface{}{
+		"views": map[string]interface{}{
+			conflictsView: map[string]string{"map": conflictsMapFunction},
+		},
+	}
+
+	_, err := r.db.Put(ConflictsDesignDoc, designDoc, NewWriteQuery().Build())
+	if dbErr, ok := err.(*CouchError); ok && dbErr.StatusCode == 409 {
+		return nil
+	}
+	return err
+}
+
+// Conflicted returns up to limit IDs of conflicted documents, starting after
+// startAfter (use "" to start from the beginning). EnsureView must have been
+// called beforehand.
+func (r *ConflictResolver) Conflicted(startAfter string, limit int) ([]string, error) {
+	params := url.Values{}
+	params.Set("limit", strconv.Itoa(limit))
+	if startAfter != "" {
+		startKey, err := json.Marshal(startAfter)
+		if err != nil {
+			return nil, err
+		}
+		params.Set("startkey", string(startKey))
+		params.Set("skip", "1")
+	}
+
+	urlStr, err := documentURL(*r.db.URL, viewPath(ConflictsDesignDoc, conflictsView), params)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := r.db.client.request("GET", urlStr, nil)
+	defer job.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	err = expectedReturnCodes(job, 200)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &conflictsViewResponse{}
+	err = json.NewDecoder(job.response.Body).Decode(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(resp.Rows))
+	for i, row := range resp.Rows {
+		ids[i] = row.ID
+	}
+	return ids, nil
+}
+
+// Resolve resolves the conflicts of a single document.
+func (r *ConflictResolver) Resolve(documentID string) (*ConflictResolution, error) {
+	openRevs, err := r.db.GetOpenRevs(documentID, nil, NewGetQuery().Revs().Build())
+	if err != nil {
+		return nil, err
+	}
+
+	leaves := []*OpenRevision{}
+	for _, openRev := range openRevs {
+		if !openRev.Missing && !openRev.Deleted {
+			leaves = append(leaves, openRev)
+		}
+	}
+	sortRevisions(leaves)
+
+	resolution := &ConflictResolution{ID: documentID}
+	if len(leaves) < 2 {
+		resolution.Skipped = true
+		return resolution, nil
+	}
+
+	merged, err := r.merge(documentID, leaves)
+	if err != nil {
+		return nil, err
+	}
+	if merged == nil {
+		resolution.Skipped = true
+		return resolution, nil
+	}
+
+	// merges returning maps must keep the fields encrypted in the leaves
+	leafDocs := make([][]byte, len(leaves))
+	for i, leaf := range leaves {
+		leafDocs[i] = leaf.Doc
+	}
+	kept, err := storedEncryptedPaths(leafDocs...)
+	if err != nil {
+		return nil, err
+	}
+
+	winner, err := r.db.resolvedDocument(merged, documentID, leaves[0].Rev, kept)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := []interface{}{winner}
+	for _, loser := range leaves[1:] {
+		docs = append(docs, map[string]interface{}{
+			"_id":      documentID,
+			"_rev":     loser.Rev,
+			"_deleted": true,
+		})
+	}
+
+	responses, err := r.db.bulkDocs(docs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, response := range responses {
+		if response.Error != "" {
+			return nil, fmt.Errorf("failed to resolve %s: %s - %s", documentID, response.Error, response.Reason)
+		}
+		if i == 0 {
+			resolution.Rev = response.Rev
+		} else {
+			resolution.Deleted = append(resolution.Deleted, leaves[i].Rev)
+		}
+	}
+
+	return resolution, nil
+}
+
+// ResolveAll sweeps the whole database, resolving every conflicted document.
+// Failures to resolve individual documents are reported in their
+// ConflictResolution rather than stopping the sweep.
+func (r *ConflictResolver) ResolveAll() ([]*ConflictResolution, error) {
+	if err := r.EnsureView(); err != nil {
+		return nil, err
+	}
+
+	batchSize := r.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	resolutions := []*ConflictResolution{}
+	startAfter := ""
+	for {
+		ids, err := r.Conflicted(startAfter, batchSize)
+		if err != nil {
+			return resolutions, err
+		}
+
+		for _, id := range ids {
+			resolution, err := r.Resolve(id)
+			if err != nil {
+				resolution = &ConflictResolution{ID: id, Error: err}
+			}
+			resolutions = append(resolutions, resolution)
+		}
+
+		if len(ids) < batchSize {
+			return resolutions, nil
+		}
+		startAfter = ids[len(ids)-1]
+	}
+}
+
+// bulkDocs performs a synchronous _bulk_docs request and decodes the results
+func (d *Database) bulkDocs(docs []interface{}) ([]BulkDocsResponse, error) {
+	job, err := UploadBulkDocs(&BulkDocsRequest{Docs: docs, NewEdits: true}, d)
+	if err != nil {
+		if job != nil {
+			job.Close()
+		}
+		return nil, err
+	}
+	defer job.Close()
+
+	err = expectedReturnCodes(job, 201, 202)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []BulkDocsResponse{}
+	err = json.NewDecoder(job.response.Body).Decode(&responses)
+
+	return responses, err
+}
+
+// resolvedDocument returns the merged document as JSON fields, encoded like
+// any other write and written on top of the winning revision. Fields in kept
+// are encrypted too.
+func (d *Database) resolvedDocument(merged interface{}, documentID, rev string, kept *encryptedPaths) (map[string]json.RawMessage, error) {
+	jsonDocument, err := d.encodeDocument(merged, false, kept)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err = json.Unmarshal(jsonDocument, &fields); err != nil {
+		return nil, err
+	}
+
+	// drop metadata copied from one of the leaves
+	delete(fields, "_conflicts")
+	delete(fields, "_revisions")
+	delete(fields, "_deleted")
+
+	fields["_id"], _ = json.Marshal(documentID)
+	fields["_rev"], _ = json.Marshal(rev)
+
+	return fields, nil
+}
+
+// sortRevisions orders revisions the way CouchDB picks a winner: highest
+// revision number first, then highest revision hash.
+func sortRevisions(revisions []*OpenRevision) {
+	sort.SliceStable(revisions, func(i, j int) bool {
+		iGen, iHash := splitRev(revisions[i].Rev)
+		jGen, jHash := splitRev(revisions[j].Rev)
+		if iGen != jGen {
+			return iGen > jGen
+		}
+		return iHash > jHash
+	})
+}
+
+func splitRev(rev string) (int, string) {
+	parts := strings.SplitN(rev, "-", 2)
+	gen, _ := strconv.Atoi(parts[0])
+	if len(parts) < 2 {
+		return gen, ""
+	}
+	return gen, parts[1]
+}
@@ -0,0 +1,95 @@
+package cloudant
+
+// QueryBuilder implementation for the Copy() API call.
+//
+// Example:
+// 	query := cloudant.NewCopyQuery().
+//     DestinationRev("3-xxxxxxx").
+//     Build()
+//
+//	meta, err := db.Copy("template", "doc-123", query)
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// CopyQueryBuilder defines the available parameter-setting functions.
+type CopyQueryBuilder interface {
+	Batch() CopyQueryBuilder
+	DestinationRev(string) CopyQueryBuilder
+	Rev(string) CopyQueryBuilder
+	W(int) CopyQueryBuilder
+	Build() *copyQuery
+}
+
+type copyQueryBuilder struct {
+	batch          bool
+	destinationRev string
+	rev            string
+	w              int
+}
+
+// copyQuery holds the implemented API call parameters. DestinationRev is sent
+// in the Destination header rather than the query string.
+type copyQuery struct {
+	Batch          bool
+	DestinationRev string
+	Rev            string
+	W              int
+}
+
+// NewCopyQuery is the entry point.
+func NewCopyQuery() CopyQueryBuilder {
+	return &copyQueryBuilder{}
+}
+
+func (c *copyQueryBuilder) Batch() CopyQueryBuilder {
+	c.batch = true
+	return c
+}
+
+// DestinationRev is the current revision of the destination document, which
+// is required to overwrite an existing document.
+func (c *copyQueryBuilder) DestinationRev(rev string) CopyQueryBuilder {
+	c.destinationRev = rev
+	return c
+}
+
+// Rev selects the revision of the source document to copy.
+func (c *copyQueryBuilder) Rev(rev string) CopyQueryBuilder {
+	c.rev = rev
+	return c
+}
+
+func (c *copyQueryBuilder) W(quorum int) CopyQueryBuilder {
+	c.w = quorum
+	return c
+}
+
+// GetQuery implements the QueryBuilder interface. It returns an
+// url.Values map with the non-default values set.
+func (cq *copyQuery) GetQuery() (url.Values, error) {
+	vals := url.Values{}
+
+	if cq.Batch {
+		vals.Set("batch", "ok")
+	}
+	if cq.Rev != "" {
+		vals.Set("rev", cq.Rev)
+	}
+	if cq.W > 0 {
+		vals.Set("w", strconv.Itoa(cq.W))
+	}
+
+	return vals, nil
+}
+
+func (c *copyQueryBuilder) Build() *copyQuery {
+	return &copyQuery{
+		Batch:          c.batch,
+		DestinationRev: c.destinationRev,
+		Rev:            c.rev,
+		W:              c.w,
+	}
+}
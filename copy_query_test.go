@@ -0,0 +1,39 @@
+package cloudant
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCopyQuery_Args(t *testing.T) {
+	// Batch          bool
+	// DestinationRev string
+	// Rev            string
+	// W              int
+
+	expectedQueryStrings := []string{
+		"batch=ok",
+		"rev=1-bf1b7e045f2843995184f78022b3d0f5",
+		"w=3",
+	}
+
+	query := NewCopyQuery().
+		Batch().
+		DestinationRev("2-aaaa").
+		Rev("1-bf1b7e045f2843995184f78022b3d0f5").
+		W(3).
+		Build()
+
+	values, _ := query.GetQuery()
+	queryString := values.Encode()
+
+	for _, str := range expectedQueryStrings {
+		if !strings.Contains(queryString, str) {
+			t.Errorf("parameter encoding not found '%s' in '%s'", str, queryString)
+			return
+		}
+	}
+	if strings.Contains(queryString, "2-aaaa") {
+		t.Errorf("destination rev found in query string '%s'", queryString)
+	}
+}
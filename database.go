@@ -5,7 +5,11 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
 	"net/url"
+	"reflect"
 	"strings"
 )
 
@@ -72,9 +76,13 @@ type ChangeRowChanges struct {
 // Database holds a reference to an authenticated client connection and the
 // name of a remote database
 type Database struct {
-	client *CouchClient
-	Name   string
-	URL    *url.URL
+	client       *CouchClient
+	Name         string
+	URL          *url.URL
+	UpdatePolicy *UpdatePolicy    // retries of Update and Upsert; nil uses DefaultUpdatePolicy
+	IDGenerator  IDGenerator      // IDs of new documents; nil leaves them to the server
+	Encryption   *FieldEncryption // client-side encryption of document fields; nil disables it
+	Limits       *Limits          // sizes of documents and requests; nil disables the checks
 }
 
 // DocumentMeta is a CouchDB id/rev pair
@@ -83,6 +91,28 @@ type DocumentMeta struct {
 	Rev string `json:"rev"`
 }
 
+// WriteResult is the outcome of a document write. Accepted is true if the
+// server acknowledged the write (202) without committing it yet, e.g. in batch
+// mode or when the write quorum was not met, and false if it was committed (201).
+type WriteResult struct {
+	DocumentMeta
+	Accepted bool
+}
+
+// DocumentHead is the metadata of a document returned by a HEAD request
+type DocumentHead struct {
+	Exists        bool
+	Rev           string // current revision, from the ETag header
+	ContentLength int64  // size of the JSON document body in bytes
+}
+
+// ConditionalGetResult is the outcome of GetIfModified. If NotModified is true
+// the document still has the given revision and the target was not written.
+type ConditionalGetResult struct {
+	NotModified bool
+	Rev         string
+}
+
 // Info represents the account meta-data
 type Info struct {
 	IsCompactRunning bool   `json:"compact_running"`
@@ -95,6 +125,34 @@ type Info struct {
 
 // All returns a channel in which AllRow types can be received.
 func (d *Database) All(args *allDocsQuery) (<-chan *AllRow, error) {
+	return d.allDocs("/_all_docs", args)
+}
+
+func (d *Database) allDocs(pathStr string, args *allDocsQuery) (<-chan *AllRow, error) {
+	lines, err := d.allDocsLines(pathStr, args)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(chan *AllRow, 1000)
+
+	go func() {
+		defer close(results)
+		for line := range lines {
+			var result = new(AllRow)
+
+			err := json.Unmarshal(line, result)
+			if err == nil {
+				results <- result
+			}
+		}
+	}()
+
+	return results, nil
+}
+
+// allDocsLines streams the raw JSON rows of an _all_docs style response
+func (d *Database) allDocsLines(pathStr string, args *allDocsQuery) (<-chan []byte, error) {
 	verb := "GET"
 	var body []byte
 	var err error
@@ -113,7 +171,7 @@ func (d *Database) All(args *allDocsQuery) (<-chan *AllRow, error) {
 		return nil, err
 	}
 
-	urlStr, err := Endpoint(*d.URL, "/_all_docs", params)
+	urlStr, err := Endpoint(*d.URL, pathStr, params)
 	if err != nil {
 		return nil, err
 	}
@@ -132,9 +190,9 @@ func (d *Database) All(args *allDocsQuery) (<-chan *AllRow, error) {
 		return nil, err
 	}
 
-	results := make(chan *AllRow, 1000)
+	results := make(chan []byte, 1000)
 
-	go func(job *Job, results chan<- *AllRow) {
+	go func(job *Job, results chan<- []byte) {
 		defer job.Close()
 
 		reader := bufio.NewReader(job.response.Body)
@@ -150,12 +208,7 @@ func (d *Database) All(args *allDocsQuery) (<-chan *AllRow, error) {
 			lineStr = strings.TrimRight(lineStr, ",") // remove trailing comma
 
 			if len(lineStr) > 7 && lineStr[0:7] == "{\"id\":\"" {
-				var result = new(AllRow)
-
-				err := json.Unmarshal([]byte(lineStr), result)
-				if err == nil {
-					results <- result
-				}
+				results <- d.decryptRow([]byte(lineStr))
 			}
 		}
 	}(job, results)
@@ -171,6 +224,38 @@ func (d *Database) Bulk(batchSize int, batchMaxBytes int, flushSecs int) *Upload
 // Changes returns a channel in which Change types can be received.
 // See: https://console.bluemix.net/docs/services/Cloudant/api/database.html#get-changes
 func (d *Database) Changes(args *changesQuery) (<-chan *Change, error) {
+	lines, err := d.changesLines(args)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(chan *Change, 1000)
+
+	go func() {
+		defer close(changes)
+		for line := range lines {
+			var change = new(ChangeRow)
+
+			err := json.Unmarshal(line, change)
+			if err == nil && len(change.Changes) == 1 {
+				changes <- &Change{
+					ID:      change.ID,
+					Rev:     change.Changes[0].Rev,
+					Seq:     change.Seq,
+					Doc:     change.Doc,
+					Deleted: change.Deleted,
+				}
+			} else {
+				fmt.Println(err)
+			}
+		}
+	}()
+
+	return changes, nil
+}
+
+// changesLines streams the raw JSON rows of a _changes response
+func (d *Database) changesLines(args *changesQuery) (<-chan []byte, error) {
 	verb := "GET"
 	var body []byte
 	var err error
@@ -205,9 +290,9 @@ func (d *Database) Changes(args *changesQuery) (<-chan *Change, error) {
 		return nil, err
 	}
 
-	changes := make(chan *Change, 1000)
+	changes := make(chan []byte, 1000)
 
-	go func(job *Job, changes chan<- *Change) {
+	go func(job *Job, changes chan<- []byte) {
 		defer job.Close()
 		defer close(changes)
 
@@ -223,20 +308,7 @@ func (d *Database) Changes(args *changesQuery) (<-chan *Change, error) {
 			lineStr = strings.TrimRight(lineStr, ",") // remove trailing comma
 
 			if len(lineStr) > 7 && lineStr[0:7] == "{\"seq\":" {
-				var change = new(ChangeRow)
-
-				err := json.Unmarshal([]byte(lineStr), change)
-				if err == nil && len(change.Changes) == 1 {
-					changes <- &Change{
-						ID:      change.ID,
-						Rev:     change.Changes[0].Rev,
-						Seq:     change.Seq,
-						Doc:     change.Doc,
-						Deleted: change.Deleted,
-					}
-				} else {
-					fmt.Println(err)
-				}
+				changes <- d.decryptRow([]byte(lineStr))
 			}
 		}
 	}(job, changes)
@@ -267,34 +339,159 @@ func (d *Database) Info() (*Info, error) {
 // Get a document from the database.
 // See: https://console.bluemix.net/docs/services/Cloudant/api/document.html#read
 func (d *Database) Get(documentID string, args *getQuery, target interface{}) error {
-	params, err := args.GetQuery()
+	jsonDocument, err := d.getRaw(documentID, args)
 	if err != nil {
 		return err
 	}
-	urlStr, err := Endpoint(*d.URL, documentID, params)
+
+	return d.decodeJSON(jsonDocument, target)
+}
+
+// getRaw gets a document as stored, without decrypting it.
+func (d *Database) getRaw(documentID string, args *getQuery) ([]byte, error) {
+	params, err := args.GetQuery()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	urlStr, err := documentURL(*d.URL, documentPath(documentID), params)
+	if err != nil {
+		return nil, err
 	}
 
 	job, err := d.client.request("GET", urlStr, nil)
 	defer job.Close()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	err = expectedReturnCodes(job, 200)
 	if err != nil {
+		return nil, err
+	}
+
+	return ioutil.ReadAll(job.response.Body)
+}
+
+// decodeDocument decodes a document, decrypting its fields if the database
+// is configured to. Tagged metadata fields are set from its '_id' and '_rev'.
+func (d *Database) decodeDocument(r io.Reader, target interface{}) error {
+	jsonDocument, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	return d.decodeJSON(jsonDocument, target)
+}
+
+// decodeJSON is decodeDocument for a document already read.
+func (d *Database) decodeJSON(jsonDocument []byte, target interface{}) error {
+	var err error
+	if d.Encryption != nil {
+		if jsonDocument, err = d.Encryption.decryptDocument(jsonDocument); err != nil {
+			return err
+		}
+	}
+
+	if err = json.Unmarshal(jsonDocument, target); err != nil {
 		return err
 	}
 
-	return json.NewDecoder(job.response.Body).Decode(target)
+	return extractDocumentMeta(jsonDocument, target)
+}
+
+// Head returns whether a document exists along with its current revision and
+// size, without downloading the document body.
+// See: https://docs.couchdb.org/en/stable/api/document/common.html#head--db-docid
+func (d *Database) Head(documentID string) (*DocumentHead, error) {
+	urlStr, err := documentURL(*d.URL, documentPath(documentID), url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := d.client.request("HEAD", urlStr, nil)
+	defer job.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	switch job.response.StatusCode {
+	case 200:
+		return &DocumentHead{
+			Exists:        true,
+			Rev:           etagRev(job.response),
+			ContentLength: job.response.ContentLength,
+		}, nil
+	case 404:
+		return &DocumentHead{}, nil
+	default:
+		return nil, &CouchError{StatusCode: job.response.StatusCode}
+	}
+}
+
+// Rev returns the current revision of a document, or a 404 CouchError if it
+// does not exist.
+func (d *Database) Rev(documentID string) (string, error) {
+	head, err := d.Head(documentID)
+	if err != nil {
+		return "", err
+	}
+
+	if !head.Exists {
+		return "", &CouchError{Err: "not_found", Reason: "missing", StatusCode: 404}
+	}
+
+	return head.Rev, nil
+}
+
+// GetIfModified gets a document only if its current revision differs from
+// rev, using an If-None-Match request. If the document is unchanged the
+// target is left untouched and the result is NotModified.
+func (d *Database) GetIfModified(documentID, rev string, args *getQuery, target interface{}) (*ConditionalGetResult, error) {
+	params, err := args.GetQuery()
+	if err != nil {
+		return nil, err
+	}
+	urlStr, err := documentURL(*d.URL, documentPath(documentID), params)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := http.Header{}
+	if rev != "" {
+		headers.Set("If-None-Match", "\""+rev+"\"")
+	}
+
+	job, err := d.client.requestWithHeaders("GET", urlStr, nil, headers)
+	defer job.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if job.response.StatusCode == 304 {
+		return &ConditionalGetResult{NotModified: true, Rev: rev}, nil
+	}
+
+	err = expectedReturnCodes(job, 200)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ConditionalGetResult{Rev: etagRev(job.response)}
+	err = d.decodeDocument(job.response.Body, target)
+
+	return result, err
+}
+
+// etagRev returns the document revision held in a response's ETag header.
+func etagRev(response *http.Response) string {
+	return strings.Trim(response.Header.Get("ETag"), "\"")
 }
 
 // Delete a document with a specified revision.
 func (d *Database) Delete(documentID, rev string) error {
 	query := url.Values{}
 	query.Add("rev", rev)
-	urlStr, err := Endpoint(*d.URL, documentID, query)
+	urlStr, err := documentURL(*d.URL, documentPath(documentID), query)
 	if err != nil {
 		return err
 	}
@@ -309,13 +506,19 @@ func (d *Database) Delete(documentID, rev string) error {
 }
 
 // Set a document. The specified type may have a json attributes '_id' and '_rev'.
-// If no '_id' is given the database will generate one for you.
+// If no '_id' is given one is assigned by the database's IDGenerator, or else
+// by the server. If document is a pointer to a struct or a map, its '_id' and
+// '_rev' are updated to the values assigned.
 func (d *Database) Set(document interface{}) (*DocumentMeta, error) {
-	jsonDocument, err := json.Marshal(document)
+	jsonDocument, err := d.encodeDocument(document, true, nil)
 	if err != nil {
 		return nil, err
 	}
 
+	if err = d.checkDocumentSize(jsonDocument); err != nil {
+		return nil, err
+	}
+
 	job, err := d.client.request("POST", d.URL.String(), bytes.NewReader(jsonDocument))
 	defer job.Close()
 
@@ -328,6 +531,176 @@ func (d *Database) Set(document interface{}) (*DocumentMeta, error) {
 		return nil, err
 	}
 
+	resp := &DocumentMeta{}
+	err = json.NewDecoder(job.response.Body).Decode(resp)
+	if err == nil {
+		SetDocumentMeta(document, resp)
+	}
+
+	return resp, err
+}
+
+// Put creates or updates the document with the given ID. The document's
+// current revision must be given either in its '_rev' attribute or with the
+// Rev() query option.
+// See: https://docs.couchdb.org/en/stable/api/document/common.html#put--db-docid
+func (d *Database) Put(documentID string, document interface{}, args *writeQuery) (*WriteResult, error) {
+	params, err := args.GetQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	urlStr, err := documentURL(*d.URL, documentPath(documentID), params)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.write("PUT", urlStr, document, false, nil)
+}
+
+// rewrite is Put for a document read from the database, whose stored
+// encrypted fields are kept encrypted whatever the type of document.
+func (d *Database) rewrite(documentID string, document interface{}, args *writeQuery, kept *encryptedPaths) (*WriteResult, error) {
+	params, err := args.GetQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	urlStr, err := documentURL(*d.URL, documentPath(documentID), params)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.write("PUT", urlStr, document, false, kept)
+}
+
+// Create creates a new document. If the document has no '_id' attribute one
+// is assigned by the database's IDGenerator, or else by the server.
+// See: https://docs.couchdb.org/en/stable/api/database/common.html#post--db
+func (d *Database) Create(document interface{}, args *writeQuery) (*WriteResult, error) {
+	params, err := args.GetQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	urlStr, err := Endpoint(*d.URL, "", params)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.write("POST", urlStr, document, true, nil)
+}
+
+func (d *Database) write(method, urlStr string, document interface{}, generateID bool, kept *encryptedPaths) (*WriteResult, error) {
+	jsonDocument, err := d.encodeDocument(document, generateID, kept)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = d.checkDocumentSize(jsonDocument); err != nil {
+		return nil, err
+	}
+
+	job, err := d.client.request(method, urlStr, bytes.NewReader(jsonDocument))
+	defer job.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	err = expectedReturnCodes(job, 201, 202)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &WriteResult{Accepted: job.response.StatusCode == 202}
+	err = json.NewDecoder(job.response.Body).Decode(&result.DocumentMeta)
+	if err == nil {
+		SetDocumentMeta(document, &result.DocumentMeta)
+	}
+
+	return result, err
+}
+
+// decryptRow decrypts the document of an _all_docs or _changes row if the
+// database is configured to. A row that fails to decrypt is logged and passed
+// on as it is rather than dropped.
+func (d *Database) decryptRow(row []byte) []byte {
+	if d.Encryption == nil {
+		return row
+	}
+
+	decrypted, err := d.Encryption.decryptRow(row)
+	if err != nil {
+		LogFunc("failed to decrypt row of %s, %s", d.Name, err)
+		return row
+	}
+	return decrypted
+}
+
+// encodeDocument marshals a document for writing, assigning it a generated ID
+// if requested and encrypting its fields if the database is configured to.
+// Metadata held in tagged fields is written as '_id' and '_rev'. kept lists
+// fields to encrypt besides those selected for the document's type, if any.
+func (d *Database) encodeDocument(document interface{}, generateID bool, kept *encryptedPaths) ([]byte, error) {
+	body := document
+	if generateID {
+		var err error
+		if body, err = d.withGeneratedID(document); err != nil {
+			return nil, err
+		}
+	}
+
+	return d.encodeBody(document, body, kept)
+}
+
+// encodeBody marshals body, which is document or a copy of it holding a
+// generated ID, encrypting the fields selected for document's type and those
+// in kept.
+func (d *Database) encodeBody(document, body interface{}, kept *encryptedPaths) ([]byte, error) {
+	jsonDocument, err := json.Marshal(body)
+	if err == nil {
+		jsonDocument, err = injectDocumentMeta(body, jsonDocument)
+	}
+	if err != nil || d.Encryption == nil {
+		return jsonDocument, err
+	}
+
+	return d.Encryption.encryptDocument(jsonDocument, reflect.TypeOf(document), kept)
+}
+
+// Copy copies a document on the server to a new or existing document. To
+// overwrite an existing document set the DestinationRev() query option.
+// See: https://docs.couchdb.org/en/stable/api/document/common.html#copy--db-docid
+func (d *Database) Copy(sourceID, destinationID string, args *copyQuery) (*DocumentMeta, error) {
+	params, err := args.GetQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	urlStr, err := documentURL(*d.URL, documentPath(sourceID), params)
+	if err != nil {
+		return nil, err
+	}
+
+	destination := documentPath(destinationID)
+	if args.DestinationRev != "" {
+		destination += "?rev=" + url.QueryEscape(args.DestinationRev)
+	}
+
+	headers := http.Header{}
+	headers.Set("Destination", destination)
+
+	job, err := d.client.requestWithHeaders("COPY", urlStr, nil, headers)
+	defer job.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	err = expectedReturnCodes(job, 201, 202)
+	if err != nil {
+		return nil, err
+	}
+
 	resp := &DocumentMeta{}
 	err = json.NewDecoder(job.response.Body).Decode(resp)
 
@@ -363,25 +736,42 @@ func (d *Database) Index(createIndexArgs *createIndex) (*CreateIndexResponse, er
 // Find performs a document query in cloudant
 // See: https://cloud.ibm.com/docs/services/Cloudant/api?topic=cloudant-query#ibm-cloudant-query-parameters
 func (d *Database) Find(findArgs *find) (*FindResponse, error) {
+	findResp := &FindResponse{}
+	err := d.find(findArgs, findResp)
+
+	return findResp, err
+}
+
+// find performs a document query, decoding the response into target
+func (d *Database) find(findArgs *find, target interface{}) error {
 	findDocument, err := json.Marshal(findArgs)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	job, err := d.client.request("POST", fmt.Sprintf("%s/%s", d.URL.String(), "_find"), bytes.NewReader(findDocument))
 	defer job.Close()
 
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	err = expectedReturnCodes(job, 200)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	findResp := &FindResponse{}
-	err = json.NewDecoder(job.response.Body).Decode(findResp)
+	if d.Encryption == nil {
+		return json.NewDecoder(job.response.Body).Decode(target)
+	}
 
-	return findResp, err
+	response, err := ioutil.ReadAll(job.response.Body)
+	if err != nil {
+		return err
+	}
+	if response, err = d.Encryption.decryptDocs(response); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(response, target)
 }
@@ -553,3 +553,175 @@ func TestDatabase_ChangesCouchDB16(t *testing.T) {
 		t.Error("failed to parse CouchDB1.6-formatted changes data")
 	}
 }
+
+func TestDatabase_Put(t *testing.T) {
+	database, err := makeDatabase()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer func() {
+		fmt.Printf("Deleting database %s", database.Name)
+		database.client.Delete(database.Name)
+	}()
+
+	doc := &struct {
+		Foo string `json:"foo"`
+	}{"mydata"}
+
+	result, err := database.Put("doc-put", doc, NewWriteQuery().Build())
+	if err != nil {
+		t.Fatalf("failed to create document: %s", err)
+	}
+	if result.ID != "doc-put" || !strings.HasPrefix(result.Rev, "1-") {
+		t.Errorf("got unexpected result on create %+v", result)
+	}
+
+	// Note: lame attempt to close inconsistency window
+	time.Sleep(500 * time.Millisecond)
+
+	result, err = database.Put("doc-put", doc, NewWriteQuery().Rev(result.Rev).Build())
+	if err != nil {
+		t.Fatalf("failed to update document: %s", err)
+	}
+	if !strings.HasPrefix(result.Rev, "2-") {
+		t.Error("got unexpected revision on update")
+	}
+
+	_, err = database.Put("doc-put", doc, NewWriteQuery().Build())
+	if dberr, ok := err.(*CouchError); !ok || dberr.StatusCode != 409 {
+		t.Errorf("expected conflict on update without rev, got %v", err)
+	}
+}
+
+func TestDatabase_CreateBatch(t *testing.T) {
+	database, err := makeDatabase()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer func() {
+		fmt.Printf("Deleting database %s", database.Name)
+		database.client.Delete(database.Name)
+	}()
+
+	doc := &struct {
+		ID  string `json:"_id"`
+		Foo string `json:"foo"`
+	}{"doc-batch", "mydata"}
+
+	result, err := database.Create(doc, NewWriteQuery().Batch().Build())
+	if err != nil {
+		t.Fatalf("failed to create document: %s", err)
+	}
+	if !result.Accepted {
+		t.Error("expected batch write to be accepted, not committed")
+	}
+	if result.ID != "doc-batch" {
+		t.Errorf("unexpected document id %s", result.ID)
+	}
+}
+
+func TestDatabase_Head(t *testing.T) {
+	database, err := makeDatabase()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer func() {
+		fmt.Printf("Deleting database %s", database.Name)
+		database.client.Delete(database.Name)
+	}()
+
+	head, err := database.Head("doc-head")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if head.Exists {
+		t.Error("unexpected document found")
+	}
+
+	meta, err := database.Set(&cloudantDocument{ID: "doc-head", Foo: "mydata", Bar: 57})
+	if err != nil {
+		t.Fatalf("failed to create document: %s", err)
+	}
+
+	// Note: lame attempt to close inconsistency window
+	time.Sleep(500 * time.Millisecond)
+
+	head, err = database.Head("doc-head")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if !head.Exists || head.Rev != meta.Rev || head.ContentLength <= 0 {
+		t.Errorf("unexpected head %+v", head)
+	}
+
+	rev, err := database.Rev("doc-head")
+	if err != nil || rev != meta.Rev {
+		t.Errorf("unexpected revision %s (%v)", rev, err)
+	}
+
+	doc := &cloudantDocument{}
+	result, err := database.GetIfModified("doc-head", meta.Rev, &getQuery{}, doc)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if !result.NotModified || doc.ID != "" {
+		t.Errorf("expected document not to be modified, got %+v", result)
+	}
+
+	result, err = database.GetIfModified("doc-head", "1-stale", &getQuery{}, doc)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if result.NotModified || result.Rev != meta.Rev || doc.Foo != "mydata" {
+		t.Errorf("expected document to be fetched, got %+v", result)
+	}
+}
+
+func TestDatabase_Copy(t *testing.T) {
+	database, err := makeDatabase()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer func() {
+		fmt.Printf("Deleting database %s", database.Name)
+		database.client.Delete(database.Name)
+	}()
+
+	_, err = database.Set(&cloudantDocument{ID: "doc-template", Foo: "mydata", Bar: 57})
+	if err != nil {
+		t.Fatalf("failed to create document: %s", err)
+	}
+
+	// Note: lame attempt to close inconsistency window
+	time.Sleep(500 * time.Millisecond)
+
+	meta, err := database.Copy("doc-template", "doc-copy", NewCopyQuery().Build())
+	if err != nil {
+		t.Fatalf("failed to copy document: %s", err)
+	}
+	if meta.ID != "doc-copy" || !strings.HasPrefix(meta.Rev, "1-") {
+		t.Errorf("unexpected copy result %+v", meta)
+	}
+
+	// Note: lame attempt to close inconsistency window
+	time.Sleep(500 * time.Millisecond)
+
+	_, err = database.Copy("doc-template", "doc-copy", NewCopyQuery().Build())
+	if dberr, ok := err.(*CouchError); !ok || dberr.StatusCode != 409 {
+		t.Errorf("expected conflict without destination rev, got %v", err)
+	}
+
+	meta, err = database.Copy("doc-template", "doc-copy", NewCopyQuery().DestinationRev(meta.Rev).Build())
+	if err != nil {
+		t.Fatalf("failed to overwrite document: %s", err)
+	}
+	if !strings.HasPrefix(meta.Rev, "2-") {
+		t.Errorf("unexpected revision on overwrite %s", meta.Rev)
+	}
+
+	doc := &cloudantDocument{}
+	err = database.Get("doc-copy", &getQuery{}, doc)
+	if err != nil || doc.Foo != "mydata" || doc.Bar != 57 {
+		t.Errorf("unexpected copied document %+v (%v)", doc, err)
+	}
+}
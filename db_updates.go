@@ -0,0 +1,181 @@
+package cloudant
+
+import (
+	"bufio"
+	"encoding/json"
+	"strings"
+)
+
+// Constants defining the possible event types in a _db_updates feed
+const (
+	// DBUpdatesCreated is a newly created database
+	DBUpdatesCreated = iota
+	// DBUpdatesUpdated is a change to the contents of an existing database
+	DBUpdatesUpdated
+	// DBUpdatesDeleted is a database deletion
+	DBUpdatesDeleted
+	// DBUpdatesHeartbeat is an empty line sent to keep the connection open
+	DBUpdatesHeartbeat
+	// DBUpdatesTerminated means far end closed the connection
+	DBUpdatesTerminated
+	// DBUpdatesError is a line of the feed that could not be decoded
+	DBUpdatesError
+)
+
+// DBUpdateRow represents a line returned by _db_updates
+type DBUpdateRow struct {
+	DBName string `json:"db_name"`
+	Type   string `json:"type"`
+	Seq    string `json:"seq"`
+}
+
+// UnmarshalJSON copes with sequence IDs being numbers on older servers and
+// opaque strings on Cloudant and CouchDB2.X.
+func (r *DBUpdateRow) UnmarshalJSON(data []byte) error {
+	type DBUpdateRow16 DBUpdateRow
+	updateRow := struct {
+		DBUpdateRow16
+		Seq json.RawMessage `json:"seq"`
+	}{DBUpdateRow16: DBUpdateRow16(*r)}
+
+	if err := json.Unmarshal(data, &updateRow); err != nil {
+		return err
+	}
+
+	*r = DBUpdateRow(updateRow.DBUpdateRow16)
+	r.Seq = ""
+	if len(updateRow.Seq) > 0 && updateRow.Seq[0] == '"' {
+		return json.Unmarshal(updateRow.Seq, &r.Seq)
+	}
+	if len(updateRow.Seq) > 0 && string(updateRow.Seq) != "null" {
+		r.Seq = string(updateRow.Seq)
+	}
+
+	return nil
+}
+
+// DBUpdateEvent is the message structure delivered by DBUpdatesFollower
+type DBUpdateEvent struct {
+	EventType int
+	DBName    string
+	Type      string // The raw update type reported by the server
+	Seq       string
+	Err       error
+}
+
+// DBUpdatesFollower follows the server-wide _db_updates feed
+type DBUpdatesFollower struct {
+	followerLifecycle
+	client *CouchClient
+	since  string
+}
+
+// dbUpdateEventType classifies an update as create, delete or update. Any
+// other server-specific types (e.g. "ddoc_updated") are reported as updates.
+func dbUpdateEventType(update *DBUpdateRow) int {
+	switch update.Type {
+	case "created":
+		return DBUpdatesCreated
+	case "deleted":
+		return DBUpdatesDeleted
+	default:
+		return DBUpdatesUpdated
+	}
+}
+
+// NewDBUpdatesFollower creates a follower on the client's _db_updates feed,
+// starting after the given sequence ID (or from now if empty).
+func NewDBUpdatesFollower(client *CouchClient, since string) *DBUpdatesFollower {
+	follower := &DBUpdatesFollower{
+		followerLifecycle: newFollowerLifecycle(),
+		client:            client,
+		since:             since,
+	}
+	return follower
+}
+
+// Since returns the sequence ID of the last update received, from which the
+// next call to Follow will resume.
+func (f *DBUpdatesFollower) Since() string {
+	return f.since
+}
+
+// Follow starts listening to the _db_updates feed
+func (f *DBUpdatesFollower) Follow() (<-chan *DBUpdateEvent, error) {
+	query := NewDBUpdatesQuery().
+		Feed("continuous").
+		Since(f.since).
+		Heartbeat(10000)
+
+	params, _ := query.Build().GetQuery()
+
+	urlStr, err := Endpoint(*f.client.rootURL, "/_db_updates", params)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := f.client.request("GET", urlStr, nil)
+	if err != nil {
+		job.Close()
+		return nil, err
+	}
+
+	err = expectedReturnCodes(job, 200)
+	if err != nil {
+		job.Close()
+		return nil, err
+	}
+
+	f.start()
+
+	updates := make(chan *DBUpdateEvent, 1000)
+	go func() {
+		defer job.Close()
+		defer close(f.stopped) // This lets consumers block until terminated
+
+		reader := bufio.NewReader(job.response.Body)
+
+		for {
+			select {
+			default:
+				line, err := reader.ReadBytes('\n')
+				if err != nil {
+					updates <- &DBUpdateEvent{EventType: DBUpdatesTerminated}
+					return
+				}
+				lineStr := strings.TrimSpace(string(line))
+				if lineStr == "" {
+					updates <- &DBUpdateEvent{EventType: DBUpdatesHeartbeat}
+					continue
+				}
+
+				update := &DBUpdateRow{}
+				err = json.Unmarshal([]byte(lineStr), update)
+				if err != nil {
+					updates <- &DBUpdateEvent{
+						EventType: DBUpdatesError,
+						Err:       err,
+					}
+					continue
+				}
+				if update.DBName == "" {
+					continue // e.g. a trailing last_seq line
+				}
+
+				if update.Seq != "" {
+					f.since = update.Seq
+				}
+				updates <- &DBUpdateEvent{
+					EventType: dbUpdateEventType(update),
+					DBName:    update.DBName,
+					Type:      update.Type,
+					Seq:       update.Seq,
+				}
+			case <-f.stop:
+				return
+			}
+		}
+	}()
+
+	return updates, nil
+}
@@ -0,0 +1,117 @@
+package cloudant
+
+// QueryBuilder implementation for the _db_updates feed.
+//
+// Example:
+// 	query := cloudant.NewDBUpdatesQuery().
+//     Feed("continuous").
+//     Heartbeat(10000).
+//     Build()
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// DBUpdatesQueryBuilder defines the available parameter-setting functions.
+type DBUpdatesQueryBuilder interface {
+	Descending() DBUpdatesQueryBuilder
+	Feed(string) DBUpdatesQueryBuilder
+	Heartbeat(int) DBUpdatesQueryBuilder
+	Limit(int) DBUpdatesQueryBuilder
+	Since(string) DBUpdatesQueryBuilder
+	Timeout(int) DBUpdatesQueryBuilder
+	Build() *dbUpdatesQuery
+}
+
+type dbUpdatesQueryBuilder struct {
+	descending bool
+	feed       string
+	heartbeat  int
+	limit      int
+	since      string
+	timeout    int
+}
+
+// dbUpdatesQuery holds the implemented API call parameters.
+type dbUpdatesQuery struct {
+	Descending bool
+	Feed       string
+	Heartbeat  int
+	Limit      int
+	Since      string
+	Timeout    int
+}
+
+// NewDBUpdatesQuery is the entry point.
+func NewDBUpdatesQuery() DBUpdatesQueryBuilder {
+	return &dbUpdatesQueryBuilder{}
+}
+
+func (d *dbUpdatesQueryBuilder) Descending() DBUpdatesQueryBuilder {
+	d.descending = true
+	return d
+}
+
+func (d *dbUpdatesQueryBuilder) Feed(feed string) DBUpdatesQueryBuilder {
+	d.feed = feed
+	return d
+}
+
+func (d *dbUpdatesQueryBuilder) Heartbeat(hb int) DBUpdatesQueryBuilder {
+	d.heartbeat = hb
+	return d
+}
+
+func (d *dbUpdatesQueryBuilder) Limit(lim int) DBUpdatesQueryBuilder {
+	d.limit = lim
+	return d
+}
+
+func (d *dbUpdatesQueryBuilder) Since(seq string) DBUpdatesQueryBuilder {
+	if seq != "" {
+		d.since = seq
+	}
+	return d
+}
+
+func (d *dbUpdatesQueryBuilder) Timeout(millis int) DBUpdatesQueryBuilder {
+	d.timeout = millis
+	return d
+}
+
+// GetQuery implements the QueryBuilder interface. It returns an
+// url.Values map with the non-default values set.
+func (dq *dbUpdatesQuery) GetQuery() (url.Values, error) {
+	vals := url.Values{}
+	if dq.Descending {
+		vals.Set("descending", "true")
+	}
+	if dq.Feed != "" {
+		vals.Set("feed", dq.Feed)
+	}
+	if dq.Heartbeat > 0 {
+		vals.Set("heartbeat", strconv.Itoa(dq.Heartbeat))
+	}
+	if dq.Limit > 0 {
+		vals.Set("limit", strconv.Itoa(dq.Limit))
+	}
+	if dq.Since != "" {
+		vals.Set("since", dq.Since)
+	}
+	if dq.Timeout > 0 {
+		vals.Set("timeout", strconv.Itoa(dq.Timeout))
+	}
+	return vals, nil
+}
+
+func (d *dbUpdatesQueryBuilder) Build() *dbUpdatesQuery {
+	return &dbUpdatesQuery{
+		Descending: d.descending,
+		Feed:       d.feed,
+		Heartbeat:  d.heartbeat,
+		Limit:      d.limit,
+		Since:      d.since,
+		Timeout:    d.timeout,
+	}
+}
@@ -0,0 +1,43 @@
+package cloudant
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDBUpdatesQuery_Args(t *testing.T) {
+	// Descending bool
+	// Feed       string
+	// Heartbeat  int
+	// Limit      int
+	// Since      string
+	// Timeout    int
+
+	expectedQueryStrings := []string{
+		"descending=true",
+		"feed=continuous",
+		"heartbeat=10000",
+		"limit=7",
+		"since=somerandomdatashouldbeSEQ",
+		"timeout=60000",
+	}
+
+	query := NewDBUpdatesQuery().
+		Descending().
+		Feed("continuous").
+		Heartbeat(10000).
+		Limit(7).
+		Since("somerandomdatashouldbeSEQ").
+		Timeout(60000).
+		Build()
+
+	values, _ := query.GetQuery()
+	queryString := values.Encode()
+
+	for _, str := range expectedQueryStrings {
+		if !strings.Contains(queryString, str) {
+			t.Errorf("parameter encoding not found '%s' in '%s'", str, queryString)
+			return
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package cloudant
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestDBUpdateRow_Seq checks that both numeric and string sequence IDs are read
+func TestDBUpdateRow_Seq(t *testing.T) {
+	data1 := []byte(`{"db_name":"mailbox","type":"created","seq":59}`)
+	data2 := []byte(`{"db_name":"mailbox","type":"created","seq":"59-g1AAAAFReJzLYWBg4MhgTmHgz8tPSTV0MDQy1zMAQsMcoARTIkOS_P___7MSGXAqSVIAkkn2IFUZzIkMuUAee5pRqkmSqRk2LTn4DTIhzaAGkEH3oQalggFIA4F8AQD7FDeD"}`)
+
+	r1 := &DBUpdateRow{}
+	if err := json.Unmarshal(data1, r1); err != nil {
+		t.Error(err)
+	}
+	if r1.Seq != "59" {
+		t.Errorf("unexpected numeric seq %s", r1.Seq)
+	}
+
+	r2 := &DBUpdateRow{}
+	if err := json.Unmarshal(data2, r2); err != nil {
+		t.Error(err)
+	}
+	if r2.DBName != "mailbox" || r2.Seq[0:3] != "59-" {
+		t.Errorf("unexpected update row %+v", r2)
+	}
+	if dbUpdateEventType(r2) != DBUpdatesCreated {
+		t.Error("unexpected event type for created database")
+	}
+}
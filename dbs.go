@@ -0,0 +1,164 @@
+package cloudant
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+var dbsInfoChunkSize = 100 // max. keys per /_dbs_info request (CouchDB default)
+
+// DBsInfoResult represents a database returned by /_dbs_info. Info is nil
+// and Error is set if the database does not exist.
+type DBsInfoResult struct {
+	Key   string `json:"key"`
+	Info  *Info  `json:"info,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// AllDBsIterator pages through /_all_dbs, fetching a page at a time.
+//
+// Example:
+//
+//	it := client.AllDBsIterator(NewAllDBsQuery().Prefix("tenant-").Build(), 500)
+//	for it.Next() {
+//		fmt.Println(it.Name())
+//	}
+//	if it.Err() != nil { ... }
+type AllDBsIterator struct {
+	client    *CouchClient
+	query     allDBsQuery
+	pageSize  int
+	remaining int
+	page      []string
+	pos       int
+	last      string
+	done      bool
+	err       error
+}
+
+// AllDBsIterator returns an iterator over the database names matching args,
+// requesting pageSize names at a time. A Limit set on args caps the total
+// number of names returned.
+func (c *CouchClient) AllDBsIterator(args *allDBsQuery, pageSize int) *AllDBsIterator {
+	if pageSize <= 0 {
+		pageSize = 1000
+	}
+
+	return &AllDBsIterator{
+		client:    c,
+		query:     *args,
+		pageSize:  pageSize,
+		remaining: args.Limit,
+	}
+}
+
+// Next advances the iterator to the next database name, returning false when
+// there are no more names or an error occurred.
+func (it *AllDBsIterator) Next() bool {
+	for it.pos >= len(it.page) {
+		if it.done || it.err != nil {
+			return false
+		}
+		it.fetch()
+	}
+
+	it.last = it.page[it.pos]
+	it.pos++
+
+	return true
+}
+
+// Name returns the current database name.
+func (it *AllDBsIterator) Name() string {
+	return it.last
+}
+
+// Err returns the first error encountered while paging.
+func (it *AllDBsIterator) Err() error {
+	return it.err
+}
+
+func (it *AllDBsIterator) fetch() {
+	query := it.query
+	query.Limit = it.pageSize
+	if it.query.Limit > 0 && it.remaining < query.Limit {
+		query.Limit = it.remaining
+	}
+	if it.last != "" {
+		// continue from the last name we have seen
+		query.StartKey = fmt.Sprintf("\"%s\"", it.last)
+		query.Skip = 1
+	}
+
+	page, err := it.client.AllDBs(&query)
+	if err != nil {
+		it.err = err
+		return
+	}
+
+	it.page = *page
+	it.pos = 0
+
+	if it.query.Limit > 0 {
+		it.remaining -= len(it.page)
+		if it.remaining <= 0 {
+			it.done = true
+		}
+	}
+	if len(it.page) < query.Limit {
+		it.done = true
+	}
+}
+
+// DBsInfo returns information about each of the named databases using
+// /_dbs_info, splitting the names into several requests if needed. Results are
+// in the same order as names.
+// See: https://docs.couchdb.org/en/stable/api/server/common.html#dbs-info
+func (c *CouchClient) DBsInfo(names []string) ([]DBsInfoResult, error) {
+	results := make([]DBsInfoResult, 0, len(names))
+
+	urlStr, err := Endpoint(*c.rootURL, "/_dbs_info", url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	for start := 0; start < len(names); start += dbsInfoChunkSize {
+		end := start + dbsInfoChunkSize
+		if end > len(names) {
+			end = len(names)
+		}
+
+		chunk, err := c.dbsInfo(urlStr, names[start:end])
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, chunk...)
+	}
+
+	return results, nil
+}
+
+func (c *CouchClient) dbsInfo(urlStr string, names []string) ([]DBsInfoResult, error) {
+	body, err := json.Marshal(map[string][]string{"keys": names})
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := c.request("POST", urlStr, bytes.NewReader(body))
+	defer job.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	err = expectedReturnCodes(job, 200)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []DBsInfoResult{}
+	err = json.NewDecoder(job.response.Body).Decode(&results)
+
+	return results, err
+}
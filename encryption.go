@@ -0,0 +1,524 @@
+package cloudant
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Prefixes of encrypted field values, followed by "<key ID>:<base64 data>"
+const (
+	encryptedPrefix     = "enc:r1:" // random nonce
+	deterministicPrefix = "enc:d1:" // nonce derived from the value
+)
+
+// KeyProvider supplies the AES keys (16, 24 or 32 bytes) used for field
+// encryption. New values are encrypted with the current key, and the ID of
+// the key is stored alongside them so that values encrypted with older keys
+// can still be decrypted after a rotation. Key IDs must not contain ':'.
+type KeyProvider interface {
+	CurrentKey() (keyID string, key []byte, err error)
+	Key(keyID string) ([]byte, error)
+	KeyIDs() []string
+}
+
+// StaticKeyProvider is a KeyProvider holding its keys in memory. To rotate,
+// add a new key and make it current; keep old keys until all documents have
+// been rewritten.
+type StaticKeyProvider struct {
+	Current string
+	Keys    map[string][]byte
+}
+
+// FieldEncryption encrypts selected document fields with AES-GCM before they
+// are written and decrypts them when documents are read. Fields are selected
+// by JSON path (e.g. "ssn" or "address.street") or with struct tags:
+// `cloudant:"encrypt"` or `cloudant:"encrypt,deterministic"`. A "*" in a path
+// matches every element of an array or value of an object, e.g.
+// "contacts.*.phone"; tagged fields inside slices and maps are found this way.
+//
+// Tags are only known when writing structs. Untyped documents (maps,
+// json.RawMessage) only have the Fields and Deterministic paths encrypted,
+// except when written back by Update, Upsert, Patch or a ConflictResolver,
+// which keep encrypted the fields that were encrypted in the revision read.
+//
+// Deterministic fields always encrypt to the same value under a given key, so
+// they can be used in _find equality selectors (see EqualitySelector), at
+// the cost of revealing which documents share a value.
+type FieldEncryption struct {
+	Keys          KeyProvider
+	Fields        []string // JSON paths of fields encrypted with a random nonce
+	Deterministic []string // JSON paths of fields encrypted deterministically
+
+	tagFields sync.Map // reflect.Type -> *encryptedPaths
+}
+
+// encryptedPaths are the JSON paths of the fields to encrypt
+type encryptedPaths struct {
+	random        []string
+	deterministic []string
+}
+
+// add adds a path unless it is already listed.
+func (p *encryptedPaths) add(path string, deterministic bool) {
+	paths := &p.random
+	if deterministic {
+		paths = &p.deterministic
+	}
+	for _, existing := range *paths {
+		if existing == path {
+			return
+		}
+	}
+	*paths = append(*paths, path)
+}
+
+// CurrentKey implements the KeyProvider interface.
+func (p *StaticKeyProvider) CurrentKey() (string, []byte, error) {
+	key, err := p.Key(p.Current)
+	return p.Current, key, err
+}
+
+// Key implements the KeyProvider interface.
+func (p *StaticKeyProvider) Key(keyID string) ([]byte, error) {
+	key, ok := p.Keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown encryption key %q", keyID)
+	}
+	return key, nil
+}
+
+// KeyIDs implements the KeyProvider interface.
+func (p *StaticKeyProvider) KeyIDs() []string {
+	keyIDs := make([]string, 0, len(p.Keys))
+	for keyID := range p.Keys {
+		keyIDs = append(keyIDs, keyID)
+	}
+	return keyIDs
+}
+
+// EncryptValue encrypts a value of a deterministic field with the current
+// key, as it is stored in documents.
+func (e *FieldEncryption) EncryptValue(field string, value interface{}) (string, error) {
+	keyID, key, err := e.Keys.CurrentKey()
+	if err != nil {
+		return "", err
+	}
+	return e.encryptValue(field, value, keyID, key, true)
+}
+
+// EqualitySelector returns a _find selector matching documents whose
+// deterministic field equals value, under any of the provider's keys.
+//
+// Example:
+//
+//	selector, err := db.Encryption.EqualitySelector("email", "jane@example.com")
+//	query := cloudant.NewFind().SetSelector("email", selector).Build()
+func (e *FieldEncryption) EqualitySelector(field string, value interface{}) (map[string]interface{}, error) {
+	values := []string{}
+	for _, keyID := range e.Keys.KeyIDs() {
+		key, err := e.Keys.Key(keyID)
+		if err != nil {
+			return nil, err
+		}
+		encrypted, err := e.encryptValue(field, value, keyID, key, true)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, encrypted)
+	}
+	return map[string]interface{}{"$in": values}, nil
+}
+
+func (e *FieldEncryption) encryptValue(field string, value interface{}, keyID string, key []byte, deterministic bool) (string, error) {
+	plaintext, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	aead, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	prefix := encryptedPrefix
+	nonce := make([]byte, aead.NonceSize())
+	if deterministic {
+		// SIV-style: the nonce is a MAC of the value, so equal values (only)
+		// give equal nonces
+		prefix = deterministicPrefix
+		mac := hmac.New(sha256.New, deriveKey(key, "deterministic-nonce"))
+		mac.Write([]byte(field))
+		mac.Write([]byte{0})
+		mac.Write(plaintext)
+		copy(nonce, mac.Sum(nil))
+	} else if _, err = rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	// the field path is authenticated so values can't be moved between fields
+	sealed := aead.Seal(nonce, nonce, plaintext, []byte(field))
+
+	return prefix + keyID + ":" + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+func (e *FieldEncryption) decryptValue(field, value string) (interface{}, error) {
+	parts := strings.SplitN(value[len(encryptedPrefix):], ":", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("malformed encrypted value in %s", field)
+	}
+
+	key, err := e.Keys.Key(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("malformed encrypted value in %s", field)
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(field))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %s", field, err)
+	}
+
+	return decodePatchJSON(plaintext)
+}
+
+// encryptDocument encrypts the configured fields, those tagged in
+// documentType and those listed in kept, if any, of a JSON document.
+func (e *FieldEncryption) encryptDocument(jsonDocument []byte, documentType reflect.Type, kept *encryptedPaths) ([]byte, error) {
+	doc, err := decodePatchJSON(jsonDocument)
+	if err != nil {
+		return nil, err
+	}
+	fields, ok := doc.(map[string]interface{})
+	if !ok {
+		return jsonDocument, nil
+	}
+
+	keyID, key, err := e.Keys.CurrentKey()
+	if err != nil {
+		return nil, err
+	}
+
+	tagged := e.taggedPaths(documentType)
+	if kept == nil {
+		kept = &encryptedPaths{}
+	}
+	for _, paths := range []struct {
+		paths         []string
+		deterministic bool
+	}{
+		{e.Fields, false},
+		{tagged.random, false},
+		{e.Deterministic, true},
+		{tagged.deterministic, true},
+		{kept.random, false},
+		{kept.deterministic, true},
+	} {
+		for _, path := range paths.paths {
+			if err = e.encryptPath(fields, strings.Split(path, "."), "", keyID, key, paths.deterministic); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return json.Marshal(fields)
+}
+
+// encryptPath encrypts the values at tokens below node, whose own path is
+// path. Values are encrypted under their actual path, with "*" standing for
+// any array index, which is what decryptTree authenticates them with.
+func (e *FieldEncryption) encryptPath(node interface{}, tokens []string, path, keyID string, key []byte, deterministic bool) error {
+	token, rest := tokens[0], tokens[1:]
+
+	encrypt := func(value interface{}, childPath string) (interface{}, error) {
+		if value == nil {
+			return nil, nil
+		}
+		if len(rest) > 0 {
+			return value, e.encryptPath(value, rest, childPath, keyID, key, deterministic)
+		}
+		if str, ok := value.(string); ok && isEncrypted(str) {
+			return value, nil // already encrypted, e.g. copied from a raw document
+		}
+		return e.encryptValue(childPath, value, keyID, key, deterministic)
+	}
+
+	switch container := node.(type) {
+	case map[string]interface{}:
+		keys := []string{token}
+		if token == "*" {
+			keys = make([]string, 0, len(container))
+			for k := range container {
+				keys = append(keys, k)
+			}
+		}
+		for _, k := range keys {
+			value, ok := container[k]
+			if !ok {
+				continue
+			}
+			encrypted, err := encrypt(value, joinPath(path, k))
+			if err != nil {
+				return err
+			}
+			container[k] = encrypted
+		}
+	case []interface{}:
+		if token != "*" {
+			return nil
+		}
+		for i, value := range container {
+			encrypted, err := encrypt(value, joinPath(path, "*"))
+			if err != nil {
+				return err
+			}
+			container[i] = encrypted
+		}
+	}
+
+	return nil
+}
+
+// decryptDocument decrypts every encrypted field of a JSON document.
+func (e *FieldEncryption) decryptDocument(jsonDocument []byte) ([]byte, error) {
+	if !bytes.Contains(jsonDocument, []byte(`"enc:`)) {
+		return jsonDocument, nil
+	}
+
+	doc, err := decodePatchJSON(jsonDocument)
+	if err != nil {
+		return nil, err
+	}
+	if doc, err = e.decryptTree(doc, ""); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(doc)
+}
+
+func (e *FieldEncryption) decryptTree(node interface{}, path string) (interface{}, error) {
+	switch value := node.(type) {
+	case string:
+		if isEncrypted(value) {
+			return e.decryptValue(path, value)
+		}
+	case map[string]interface{}:
+		for key, child := range value {
+			decrypted, err := e.decryptTree(child, joinPath(path, key))
+			if err != nil {
+				return nil, err
+			}
+			value[key] = decrypted
+		}
+	case []interface{}:
+		for i, child := range value {
+			decrypted, err := e.decryptTree(child, joinPath(path, "*"))
+			if err != nil {
+				return nil, err
+			}
+			value[i] = decrypted
+		}
+	}
+	return node, nil
+}
+
+// storedEncryptedPaths returns the paths of the encrypted fields of JSON
+// documents as stored, so that they can be encrypted again when rewritten.
+func storedEncryptedPaths(jsonDocuments ...[]byte) (*encryptedPaths, error) {
+	paths := &encryptedPaths{}
+	for _, jsonDocument := range jsonDocuments {
+		if !bytes.Contains(jsonDocument, []byte(`"enc:`)) {
+			continue
+		}
+		doc, err := decodePatchJSON(jsonDocument)
+		if err != nil {
+			return nil, err
+		}
+		collectEncryptedPaths(doc, "", paths)
+	}
+	return paths, nil
+}
+
+func collectEncryptedPaths(node interface{}, path string, paths *encryptedPaths) {
+	switch value := node.(type) {
+	case string:
+		if isEncrypted(value) && path != "" {
+			paths.add(path, strings.HasPrefix(value, deterministicPrefix))
+		}
+	case map[string]interface{}:
+		for key, child := range value {
+			collectEncryptedPaths(child, joinPath(path, key), paths)
+		}
+	case []interface{}:
+		for _, child := range value {
+			collectEncryptedPaths(child, joinPath(path, "*"), paths)
+		}
+	}
+}
+
+func joinPath(path, token string) string {
+	if path == "" {
+		return token
+	}
+	return path + "." + token
+}
+
+// decryptRow decrypts the "doc" of an _all_docs or _changes row.
+func (e *FieldEncryption) decryptRow(row []byte) ([]byte, error) {
+	if !bytes.Contains(row, []byte(`"enc:`)) {
+		return row, nil
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(row, &fields); err != nil {
+		return nil, err
+	}
+	doc, ok := fields["doc"]
+	if !ok {
+		return row, nil
+	}
+
+	doc, err := e.decryptDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	fields["doc"] = doc
+
+	return json.Marshal(fields)
+}
+
+// decryptDocs decrypts the "docs" of a _find response.
+func (e *FieldEncryption) decryptDocs(response []byte) ([]byte, error) {
+	if !bytes.Contains(response, []byte(`"enc:`)) {
+		return response, nil
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(response, &fields); err != nil {
+		return nil, err
+	}
+	docs := []json.RawMessage{}
+	if err := json.Unmarshal(fields["docs"], &docs); err != nil {
+		return nil, err
+	}
+
+	for i, doc := range docs {
+		decrypted, err := e.decryptDocument(doc)
+		if err != nil {
+			return nil, err
+		}
+		docs[i] = decrypted
+	}
+
+	var err error
+	fields["docs"], err = json.Marshal(docs)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(fields)
+}
+
+// taggedPaths returns the JSON paths of the fields of a struct type tagged
+// for encryption.
+func (e *FieldEncryption) taggedPaths(documentType reflect.Type) *encryptedPaths {
+	if documentType == nil {
+		return &encryptedPaths{}
+	}
+	if paths, ok := e.tagFields.Load(documentType); ok {
+		return paths.(*encryptedPaths)
+	}
+
+	paths := &encryptedPaths{}
+	collectTaggedPaths(documentType, "", paths, map[reflect.Type]bool{})
+	e.tagFields.Store(documentType, paths)
+
+	return paths
+}
+
+func collectTaggedPaths(t reflect.Type, prefix string, paths *encryptedPaths, seen map[reflect.Type]bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		// fields of elements, or of map values, are under a "*"
+		if prefix != "" {
+			collectTaggedPaths(t.Elem(), prefix+"*.", paths, seen)
+		}
+		return
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return
+	}
+	seen[t] = true
+	defer delete(seen, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		jsonName := strings.Split(field.Tag.Get("json"), ",")[0]
+		if jsonName == "-" {
+			continue
+		}
+		if field.Anonymous && jsonName == "" {
+			collectTaggedPaths(field.Type, prefix, paths, seen)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+
+		switch field.Tag.Get("cloudant") {
+		case "encrypt":
+			paths.random = append(paths.random, prefix+jsonName)
+		case "encrypt,deterministic":
+			paths.deterministic = append(paths.deterministic, prefix+jsonName)
+		default:
+			collectTaggedPaths(field.Type, prefix+jsonName+".", paths, seen)
+		}
+	}
+}
+
+func isEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix) || strings.HasPrefix(value, deterministicPrefix)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// deriveKey derives a subkey for a given purpose from an encryption key
+func deriveKey(key []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
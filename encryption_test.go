@@ -0,0 +1,425 @@
+package cloudant
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+type encryptedAddress struct {
+	Street string `json:"street" cloudant:"encrypt"`
+	City   string `json:"city"`
+}
+
+type encryptedDocument struct {
+	ID      string           `json:"_id"`
+	Name    string           `json:"name"`
+	SSN     string           `json:"ssn" cloudant:"encrypt"`
+	Email   string           `json:"email" cloudant:"encrypt,deterministic"`
+	Address encryptedAddress `json:"address"`
+	Notes   []string         `json:"notes"`
+}
+
+type encryptedContact struct {
+	Name  string `json:"name"`
+	Phone string `json:"phone" cloudant:"encrypt"`
+}
+
+type encryptedContacts struct {
+	ID       string                      `json:"_id"`
+	Contacts []encryptedContact          `json:"contacts"`
+	ByName   map[string]encryptedContact `json:"by_name"`
+	Tags     [][]*encryptedContact       `json:"tags"`
+}
+
+func testEncryption() *FieldEncryption {
+	return &FieldEncryption{
+		Keys: &StaticKeyProvider{
+			Current: "k1",
+			Keys:    map[string][]byte{"k1": []byte("0123456789abcdef0123456789abcdef")},
+		},
+		Fields: []string{"notes"},
+	}
+}
+
+func TestEncryption_TaggedPaths(t *testing.T) {
+	paths := testEncryption().taggedPaths(reflect.TypeOf(&encryptedDocument{}))
+
+	if strings.Join(paths.random, ",") != "ssn,address.street" || strings.Join(paths.deterministic, ",") != "email" {
+		t.Errorf("unexpected paths %+v", paths)
+	}
+}
+
+func TestEncryption_TaggedPathsNested(t *testing.T) {
+	paths := testEncryption().taggedPaths(reflect.TypeOf(&encryptedContacts{}))
+
+	if strings.Join(paths.random, ",") != "contacts.*.phone,by_name.*.phone,tags.*.*.phone" {
+		t.Errorf("unexpected paths %+v", paths)
+	}
+}
+
+func TestEncryption_RoundTripNested(t *testing.T) {
+	encryption := testEncryption()
+	doc := &encryptedContacts{
+		ID:       "doc",
+		Contacts: []encryptedContact{{Name: "Jane", Phone: "555-0100"}, {Name: "John", Phone: "555-0101"}},
+		ByName:   map[string]encryptedContact{"jane": {Name: "Jane", Phone: "555-0102"}},
+		Tags:     [][]*encryptedContact{{{Name: "Jim", Phone: "555-0103"}, nil}},
+	}
+
+	jsonDocument, _ := json.Marshal(doc)
+	encrypted, err := encryption.encryptDocument(jsonDocument, reflect.TypeOf(doc), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, plaintext := range []string{"555-0100", "555-0101", "555-0102", "555-0103"} {
+		if strings.Contains(string(encrypted), plaintext) {
+			t.Errorf("found %s in encrypted document %s", plaintext, encrypted)
+		}
+	}
+	if !strings.Contains(string(encrypted), `"name":"Jane"`) {
+		t.Errorf("expected other fields in clear, found %s", encrypted)
+	}
+
+	decrypted, err := encryption.decryptDocument(encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := &encryptedContacts{}
+	json.Unmarshal(decrypted, result)
+	if !reflect.DeepEqual(result, doc) {
+		t.Errorf("Expected %+v, found %+v", doc, result)
+	}
+}
+
+func TestEncryption_StoredPaths(t *testing.T) {
+	encryption := testEncryption()
+	jsonDocument, _ := json.Marshal(&encryptedDocument{ID: "doc", SSN: "123-45-6789", Email: "jane@example.com"})
+	first, _ := encryption.encryptDocument(jsonDocument, reflect.TypeOf(&encryptedDocument{}), nil)
+	jsonDocument, _ = json.Marshal(&encryptedContacts{ID: "doc", Contacts: []encryptedContact{{Phone: "555-0100"}}})
+	second, _ := encryption.encryptDocument(jsonDocument, reflect.TypeOf(&encryptedContacts{}), nil)
+
+	paths, err := storedEncryptedPaths(first, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(paths.random)
+	if strings.Join(paths.random, ",") != "address.street,contacts.*.phone,ssn" || strings.Join(paths.deterministic, ",") != "email" {
+		t.Errorf("unexpected paths %+v", paths)
+	}
+
+	// a map written back with those paths is encrypted again
+	doc := map[string]interface{}{"_id": "doc", "ssn": "987-65-4321", "contacts": []interface{}{map[string]interface{}{"phone": "555-0199"}}}
+	jsonDocument, _ = json.Marshal(doc)
+	encrypted, err := encryption.encryptDocument(jsonDocument, reflect.TypeOf(doc), paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(encrypted), "987-65-4321") || strings.Contains(string(encrypted), "555-0199") {
+		t.Errorf("expected kept paths to be encrypted, found %s", encrypted)
+	}
+}
+
+func TestEncryption_RoundTrip(t *testing.T) {
+	encryption := testEncryption()
+	doc := &encryptedDocument{
+		ID:      "doc",
+		Name:    "Jane",
+		SSN:     "123-45-6789",
+		Email:   "jane@example.com",
+		Address: encryptedAddress{Street: "1 Main St", City: "Springfield"},
+		Notes:   []string{"private"},
+	}
+
+	jsonDocument, _ := json.Marshal(doc)
+	encrypted, err := encryption.encryptDocument(jsonDocument, reflect.TypeOf(doc), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, plaintext := range []string{"123-45-6789", "jane@example.com", "1 Main St", "private"} {
+		if strings.Contains(string(encrypted), plaintext) {
+			t.Errorf("found %s in encrypted document %s", plaintext, encrypted)
+		}
+	}
+	if !strings.Contains(string(encrypted), `"name":"Jane"`) || !strings.Contains(string(encrypted), `"city":"Springfield"`) {
+		t.Errorf("expected other fields in clear, found %s", encrypted)
+	}
+
+	decrypted, err := encryption.decryptDocument(encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := &encryptedDocument{}
+	json.Unmarshal(decrypted, result)
+	if !reflect.DeepEqual(result, doc) {
+		t.Errorf("Expected %+v, found %+v", doc, result)
+	}
+}
+
+func TestEncryption_Deterministic(t *testing.T) {
+	encryption := testEncryption()
+
+	first, _ := encryption.EncryptValue("email", "jane@example.com")
+	second, _ := encryption.EncryptValue("email", "jane@example.com")
+	other, _ := encryption.EncryptValue("email", "john@example.com")
+	if first != second || first == other {
+		t.Errorf("unexpected deterministic values %s, %s, %s", first, second, other)
+	}
+
+	// values are bound to their field
+	if _, err := encryption.decryptValue("name", first); err == nil {
+		t.Error("expected error decrypting a value moved to another field")
+	}
+}
+
+func TestEncryption_KeyRotation(t *testing.T) {
+	encryption := testEncryption()
+	old, _ := encryption.EncryptValue("email", "jane@example.com")
+
+	keys := encryption.Keys.(*StaticKeyProvider)
+	keys.Keys["k2"] = []byte("fedcba9876543210")
+	keys.Current = "k2"
+
+	current, _ := encryption.EncryptValue("email", "jane@example.com")
+	if !strings.HasPrefix(current, deterministicPrefix+"k2:") {
+		t.Errorf("expected value encrypted with the new key, found %s", current)
+	}
+
+	value, err := encryption.decryptValue("email", old)
+	if err != nil || value != "jane@example.com" {
+		t.Errorf("failed to decrypt value encrypted with the old key: %v (%v)", value, err)
+	}
+
+	selector, err := encryption.EqualitySelector("email", "jane@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := selector["$in"].([]string)
+	if len(values) != 2 || (values[0] != old && values[1] != old) || (values[0] != current && values[1] != current) {
+		t.Errorf("unexpected selector %v", selector)
+	}
+}
+
+func TestDatabase_Encryption(t *testing.T) {
+	database, err := makeDatabase()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer func() {
+		fmt.Printf("Deleting database %s", database.Name)
+		database.client.Delete(database.Name)
+	}()
+
+	database.Encryption = testEncryption()
+
+	_, err = database.Set(&encryptedDocument{ID: "doc-encrypted", SSN: "123-45-6789", Email: "jane@example.com"})
+	if err != nil {
+		t.Fatalf("failed to create document: %s", err)
+	}
+
+	// Note: lame attempt to close inconsistency window
+	time.Sleep(500 * time.Millisecond)
+
+	doc := &encryptedDocument{}
+	if err = database.Get("doc-encrypted", &getQuery{}, doc); err != nil || doc.SSN != "123-45-6789" {
+		t.Errorf("unexpected decrypted document %+v (%v)", doc, err)
+	}
+
+	raw := map[string]interface{}{}
+	database.Encryption = nil
+	if err = database.Get("doc-encrypted", &getQuery{}, &raw); err != nil || raw["ssn"] == "123-45-6789" {
+		t.Errorf("expected encrypted field in stored document, found %v (%v)", raw, err)
+	}
+	database.Encryption = testEncryption()
+
+	selector, err := database.Encryption.EqualitySelector("email", "jane@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	found, err := database.Find(NewFind().SetSelector("email", selector).Build())
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(found.Docs) != 1 || found.Docs[0].(map[string]interface{})["ssn"] != "123-45-6789" {
+		t.Errorf("unexpected find response %+v", found.Docs)
+	}
+}
+
+func TestDatabase_EncryptionPutWithAttachments(t *testing.T) {
+	var body string
+	client, server := makeMockClient(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(201)
+		w.Write([]byte(`{"ok":true,"id":"doc","rev":"1-a"}`))
+	})
+	defer server.Close()
+
+	database, err := client.Get("db")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	database.Encryption = testEncryption()
+
+	doc := &encryptedDocument{ID: "doc", SSN: "123-45-6789"}
+	attachments := []Attachment{{Name: "a.txt", ContentType: "text/plain", Body: bytes.NewReader([]byte("content"))}}
+	if _, err = database.PutWithAttachments(doc, attachments); err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	if strings.Contains(body, "123-45-6789") || !strings.Contains(body, `"ssn":"enc:`) {
+		t.Errorf("expected an encrypted ssn, found %s", body)
+	}
+}
+
+func TestDatabase_EncryptionGetIfModified(t *testing.T) {
+	encryption := testEncryption()
+	jsonDocument, _ := json.Marshal(&encryptedDocument{ID: "doc", SSN: "123-45-6789"})
+	encrypted, err := encryption.encryptDocument(jsonDocument, reflect.TypeOf(&encryptedDocument{}), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, server := makeMockClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"2-b"`)
+		w.Write(encrypted)
+	})
+	defer server.Close()
+
+	database, err := client.Get("db")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	database.Encryption = encryption
+
+	doc := &encryptedDocument{}
+	result, err := database.GetIfModified("doc", "1-a", &getQuery{}, doc)
+	if err != nil || result.NotModified || doc.SSN != "123-45-6789" {
+		t.Errorf("unexpected decrypted document %+v (%+v, %v)", doc, result, err)
+	}
+}
+
+func TestDatabase_EncryptionBulkGet(t *testing.T) {
+	encryption := testEncryption()
+	jsonDocument, _ := json.Marshal(&encryptedDocument{ID: "doc", SSN: "123-45-6789"})
+	encrypted, err := encryption.encryptDocument(jsonDocument, reflect.TypeOf(&encryptedDocument{}), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, server := makeMockClient(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"results":[{"id":"doc","docs":[{"ok":%s}]}]}`, encrypted)
+	})
+	defer server.Close()
+
+	database, err := client.Get("db")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	database.Encryption = encryption
+
+	results, err := database.BulkGet([]BulkGetRequest{{ID: "doc"}}, NewBulkGetQuery().Build())
+	if err != nil || len(results) != 1 {
+		t.Fatalf("unexpected results %+v (%v)", results, err)
+	}
+	doc := &encryptedDocument{}
+	if err = results[0].Decode(doc); err != nil || doc.SSN != "123-45-6789" {
+		t.Errorf("unexpected decrypted document %+v (%v)", doc, err)
+	}
+}
+
+func TestDatabase_EncryptionUntypedWrites(t *testing.T) {
+	encryption := testEncryption()
+	jsonDocument, _ := json.Marshal(&encryptedDocument{ID: "doc", SSN: "123-45-6789", Email: "jane@example.com"})
+	encrypted, err := encryption.encryptDocument(jsonDocument, reflect.TypeOf(&encryptedDocument{}), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := strings.Replace(string(encrypted), `"_id":"doc"`, `"_id":"doc","_rev":"1-a"`, 1)
+
+	var body string
+	client, server := makeMockClient(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			w.Write([]byte(stored))
+		case "PUT":
+			b, _ := ioutil.ReadAll(r.Body)
+			body = string(b)
+			w.WriteHeader(201)
+			w.Write([]byte(`{"ok":true,"id":"doc","rev":"2-b"}`))
+		}
+	})
+	defer server.Close()
+
+	database, err := client.Get("db")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	database.Encryption = encryption
+
+	check := func(name string) {
+		for _, plaintext := range []string{"123-45-6789", "jane@example.com", "987-65-4321"} {
+			if strings.Contains(body, plaintext) {
+				t.Errorf("%s: found %s in document sent %s", name, plaintext, body)
+			}
+		}
+		if !strings.Contains(body, `"ssn":"enc:r1:`) || !strings.Contains(body, `"email":"enc:d1:`) {
+			t.Errorf("%s: expected encrypted fields, found %s", name, body)
+		}
+	}
+
+	if _, err = database.Patch("doc", MergePatch{"name": "Jane"}); err != nil {
+		t.Fatalf("%s", err)
+	}
+	check("Patch")
+
+	doc := map[string]interface{}{}
+	_, err = database.Update("doc", &doc, func(target interface{}) error {
+		(*target.(*map[string]interface{}))["ssn"] = "987-65-4321"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	check("Update")
+}
+
+func TestDatabase_EncryptionUndecryptableRow(t *testing.T) {
+	other := testEncryption()
+	other.Keys = &StaticKeyProvider{Current: "k2", Keys: map[string][]byte{"k2": []byte("fedcba9876543210fedcba9876543210")}}
+	jsonDocument, _ := json.Marshal(&encryptedDocument{ID: "doc", SSN: "123-45-6789"})
+	encrypted, err := other.encryptDocument(jsonDocument, reflect.TypeOf(&encryptedDocument{}), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, server := makeMockClient(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "{\"total_rows\":1,\"offset\":0,\"rows\":[\n{\"id\":\"doc\",\"key\":\"doc\",\"value\":{\"rev\":\"1-a\"},\"doc\":%s}\n]}\n", encrypted)
+	})
+	defer server.Close()
+
+	database, err := client.Get("db")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	database.Encryption = testEncryption()
+
+	rows, err := database.All(NewAllDocsQuery().IncludeDocs().Build())
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	ids := []string{}
+	for row := range rows {
+		ids = append(ids, row.ID)
+	}
+	if len(ids) != 1 || ids[0] != "doc" {
+		t.Errorf("expected the row to be passed on undecrypted, found %v", ids)
+	}
+}
@@ -30,11 +30,38 @@ type ChangeEvent struct {
 	Err       error
 }
 
+// followerLifecycle lets a follower's reader goroutine be told to stop, and
+// lets the caller block until it has done so
+type followerLifecycle struct {
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+func newFollowerLifecycle() followerLifecycle {
+	return followerLifecycle{
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+// start prepares the lifecycle for a new reader goroutine. Fresh channels
+// are needed as a previous Close closed stop, and the previous reader closed
+// its own stopped on termination.
+func (l *followerLifecycle) start() {
+	l.stop = make(chan struct{})
+	l.stopped = make(chan struct{})
+}
+
+// Close will terminate the follower
+func (l *followerLifecycle) Close() {
+	close(l.stop)
+	<-l.stopped
+}
+
 // Follower is the orchestrator
 type Follower struct {
+	followerLifecycle
 	db          *Database
-	stop        chan struct{}
-	stopped     chan struct{}
 	since       string
 	seqInterval int
 }
@@ -55,20 +82,13 @@ func eventType(change *ChangeRow) int {
 // NewFollower creates a Follower on database's changes
 func NewFollower(database *Database, interval int) *Follower {
 	follower := &Follower{
-		db:          database,
-		stop:        make(chan struct{}),
-		stopped:     make(chan struct{}),
-		seqInterval: interval,
+		followerLifecycle: newFollowerLifecycle(),
+		db:                database,
+		seqInterval:       interval,
 	}
 	return follower
 }
 
-// Close will terminate the Follower
-func (f *Follower) Close() {
-	close(f.stop)
-	<-f.stopped
-}
-
 // Follow starts listening to the changes feed
 func (f *Follower) Follow() (<-chan *ChangeEvent, error) {
 	query := NewChangesQuery().
@@ -101,6 +121,8 @@ func (f *Follower) Follow() (<-chan *ChangeEvent, error) {
 		return nil, err
 	}
 
+	f.start()
+
 	changes := make(chan *ChangeEvent, 1000)
 	go func() {
 		defer job.Close()
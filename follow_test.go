@@ -0,0 +1,38 @@
+package cloudant
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestFollower_Restart checks that a follower can follow again after Close
+func TestFollower_Restart(t *testing.T) {
+	client, server := makeMockClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"seq":1,"id":"doc","changes":[{"rev":"1-x"}]}` + "\n"))
+	})
+	defer server.Close()
+
+	database, err := client.Get("db")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	follower := NewFollower(database, 0)
+	for i := 0; i < 2; i++ {
+		changes, err := follower.Follow()
+		if err != nil {
+			t.Fatalf("%s", err)
+		}
+
+		select {
+		case event := <-changes:
+			if event.EventType != ChangesInsert || event.Meta.ID != "doc" {
+				t.Errorf("run %d: unexpected event %+v", i, event)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("run %d: no event received", i)
+		}
+		follower.Close()
+	}
+}
@@ -1,5 +1,5 @@
 module github.com/kylej-ibm/go-cloudant
 
-go 1.13
+go 1.18
 
 require github.com/joho/godotenv v1.3.0
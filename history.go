@@ -0,0 +1,49 @@
+package cloudant
+
+import (
+	"fmt"
+)
+
+// RevisionStatus is the availability of a revision in a document's history
+type RevisionStatus string
+
+// Constants defining the possible revision statuses
+const (
+	RevisionAvailable RevisionStatus = "available" // the body can still be fetched
+	RevisionMissing   RevisionStatus = "missing"   // the body was removed by compaction
+	RevisionDeleted   RevisionStatus = "deleted"   // the revision is a deletion
+)
+
+// RevisionInfo is an entry in a document's revision history
+type RevisionInfo struct {
+	Rev    string         `json:"rev"`
+	Status RevisionStatus `json:"status"`
+}
+
+// revsInfoDoc holds the _revs_info of a document
+type revsInfoDoc struct {
+	RevsInfo []RevisionInfo `json:"_revs_info"`
+}
+
+// History returns the revision history of a document's winning revision,
+// newest first. Bodies of revisions with RevisionAvailable status can be
+// fetched with GetRevision until the database is compacted.
+// See: https://docs.couchdb.org/en/stable/api/document/common.html#obtaining-an-extended-revision-history
+func (d *Database) History(documentID string) ([]RevisionInfo, error) {
+	doc := &revsInfoDoc{}
+	err := d.Get(documentID, NewGetQuery().RevsInfo().Build(), doc)
+	if err != nil {
+		return nil, err
+	}
+	return doc.RevsInfo, nil
+}
+
+// GetRevision gets the body of a specific revision of a document, which may
+// be an older one from its History.
+func (d *Database) GetRevision(documentID, rev string, target interface{}) error {
+	if rev == "" {
+		return fmt.Errorf("missing revision of %s", documentID)
+	}
+
+	return d.Get(documentID, NewGetQuery().Rev(rev).Build(), target)
+}
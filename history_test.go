@@ -0,0 +1,65 @@
+package cloudant
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestHistory_RevsInfo(t *testing.T) {
+	data := `{"_id":"doc","_rev":"3-c","_revs_info":[{"rev":"3-c","status":"available"},{"rev":"2-b","status":"missing"},{"rev":"1-a","status":"available"}]}`
+
+	doc := &revsInfoDoc{}
+	if err := json.Unmarshal([]byte(data), doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.RevsInfo) != 3 || doc.RevsInfo[1].Rev != "2-b" || doc.RevsInfo[1].Status != RevisionMissing {
+		t.Errorf("unexpected history %+v", doc.RevsInfo)
+	}
+}
+
+func TestDatabase_History(t *testing.T) {
+	database, err := makeDatabase()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer func() {
+		fmt.Printf("Deleting database %s", database.Name)
+		database.client.Delete(database.Name)
+	}()
+
+	first, err := database.Set(&cloudantDocument{ID: "doc-history", Foo: "first", Bar: 1})
+	if err != nil {
+		t.Fatalf("failed to create document: %s", err)
+	}
+
+	// Note: lame attempt to close inconsistency window
+	time.Sleep(500 * time.Millisecond)
+
+	second, err := database.Set(&struct {
+		cloudantDocument
+		Rev string `json:"_rev"`
+	}{cloudantDocument{ID: "doc-history", Foo: "second", Bar: 2}, first.Rev})
+	if err != nil {
+		t.Fatalf("failed to update document: %s", err)
+	}
+
+	time.Sleep(500 * time.Millisecond)
+
+	history, err := database.History("doc-history")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(history) != 2 || history[0].Rev != second.Rev || history[1].Rev != first.Rev {
+		t.Fatalf("unexpected history %+v", history)
+	}
+
+	if history[1].Status == RevisionAvailable {
+		doc := &cloudantDocument{}
+		err = database.GetRevision("doc-history", history[1].Rev, doc)
+		if err != nil || doc.Foo != "first" {
+			t.Errorf("unexpected old revision %+v (%v)", doc, err)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package cloudant
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var uuidPrefetchCount = 100 // UUIDs fetched ahead of time by CouchClient.UUIDs
+var uuidMaxCount = 1000     // max. UUIDs per /_uuids request (CouchDB default)
+
+// IDGenerator assigns IDs to new documents on the client side. Set on a
+// Database, it is used by Set, Create and the Uploader for documents without
+// an '_id'.
+type IDGenerator interface {
+	NewID(document interface{}) (string, error)
+}
+
+// RandomIDGenerator generates random 32 character hex IDs, like the server.
+type RandomIDGenerator struct{}
+
+// TimeOrderedIDGenerator generates ULID-like IDs: 26 characters that sort by
+// creation time, so documents created together are stored close together.
+// IDs generated within the same millisecond are strictly increasing.
+type TimeOrderedIDGenerator struct {
+	mutex  sync.Mutex
+	lastMS int64
+	last   [10]byte
+}
+
+// PartitionedIDGenerator generates IDs for partitioned databases in the form
+// "partition:id". Partition returns the partition key of a document and IDs
+// generates the part after the colon.
+type PartitionedIDGenerator struct {
+	Partition func(document interface{}) string
+	IDs       IDGenerator
+}
+
+// ServerIDGenerator assigns UUIDs fetched from the server's /_uuids endpoint.
+type ServerIDGenerator struct {
+	client *CouchClient
+}
+
+// uuidCache holds UUIDs fetched ahead of time from /_uuids
+type uuidCache struct {
+	mutex sync.Mutex
+	uuids []string
+}
+
+// uuidsResponse is the response from the /_uuids endpoint
+type uuidsResponse struct {
+	UUIDs []string `json:"uuids"`
+}
+
+// UUIDs returns n UUIDs generated by the server. UUIDs are fetched in batches
+// and cached, so most calls don't need a request.
+// See: https://docs.couchdb.org/en/stable/api/server/common.html#uuids
+func (c *CouchClient) UUIDs(n int) ([]string, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("invalid number of UUIDs %d", n)
+	}
+
+	for {
+		if uuids := c.uuids.take(n); uuids != nil {
+			return uuids, nil
+		}
+
+		c.uuids.mutex.Lock()
+		count := n - len(c.uuids.uuids) + uuidPrefetchCount
+		c.uuids.mutex.Unlock()
+		if count > uuidMaxCount {
+			count = uuidMaxCount
+		}
+
+		params := url.Values{}
+		params.Set("count", strconv.Itoa(count))
+
+		// the cache isn't locked during the request, so that callers served
+		// from it don't wait; concurrent fetches only cache more UUIDs
+		resp := &uuidsResponse{}
+		if err := c.getJSON("/_uuids", params, resp); err != nil {
+			return nil, err
+		}
+		if len(resp.UUIDs) == 0 {
+			return nil, fmt.Errorf("no UUIDs returned by the server")
+		}
+
+		c.uuids.mutex.Lock()
+		c.uuids.uuids = append(c.uuids.uuids, resp.UUIDs...)
+		c.uuids.mutex.Unlock()
+	}
+}
+
+// take removes n UUIDs from the cache, or returns nil if it holds fewer.
+func (u *uuidCache) take(n int) []string {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	if len(u.uuids) < n {
+		return nil
+	}
+
+	uuids := make([]string, n)
+	copy(uuids, u.uuids)
+	u.uuids = u.uuids[n:]
+
+	return uuids
+}
+
+// NewServerIDGenerator creates an IDGenerator using the server's UUIDs.
+func NewServerIDGenerator(client *CouchClient) *ServerIDGenerator {
+	return &ServerIDGenerator{client: client}
+}
+
+// NewID implements the IDGenerator interface.
+func (g *ServerIDGenerator) NewID(document interface{}) (string, error) {
+	uuids, err := g.client.UUIDs(1)
+	if err != nil {
+		return "", err
+	}
+	return uuids[0], nil
+}
+
+// NewID implements the IDGenerator interface.
+func (g RandomIDGenerator) NewID(document interface{}) (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// NewID implements the IDGenerator interface.
+func (g *TimeOrderedIDGenerator) NewID(document interface{}) (string, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	ms := time.Now().UnixNano() / int64(time.Millisecond)
+	if ms > g.lastMS {
+		if _, err := rand.Read(g.last[:]); err != nil {
+			return "", err
+		}
+		g.lastMS = ms
+	} else {
+		// same millisecond (or clock went backwards): increment the random part
+		for i := len(g.last) - 1; i >= 0; i-- {
+			g.last[i]++
+			if g.last[i] != 0 {
+				break
+			}
+		}
+	}
+
+	id := make([]byte, 16)
+	for i := 0; i < 6; i++ {
+		id[i] = byte(g.lastMS >> uint(8*(5-i)))
+	}
+	copy(id[6:], g.last[:])
+
+	return encodeCrockford(id), nil
+}
+
+// NewID implements the IDGenerator interface.
+func (g *PartitionedIDGenerator) NewID(document interface{}) (string, error) {
+	if g.Partition == nil {
+		return "", fmt.Errorf("PartitionedIDGenerator has no Partition function")
+	}
+	partition := g.Partition(document)
+	if partition == "" {
+		return "", fmt.Errorf("no partition key for document")
+	}
+
+	ids := g.IDs
+	if ids == nil {
+		ids = RandomIDGenerator{}
+	}
+
+	id, err := ids.NewID(document)
+	if err != nil {
+		return "", err
+	}
+	return partition + ":" + id, nil
+}
+
+// encodeCrockford encodes 16 bytes as 26 characters of Crockford's base32,
+// as used by ULIDs.
+func encodeCrockford(id []byte) string {
+	const alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+	n := new(big.Int).SetBytes(id)
+	mod := new(big.Int)
+	base := big.NewInt(32)
+
+	encoded := make([]byte, 26)
+	for i := len(encoded) - 1; i >= 0; i-- {
+		n.DivMod(n, base, mod)
+		encoded[i] = alphabet[mod.Int64()]
+	}
+	return string(encoded)
+}
+
+// withGeneratedID assigns a generated ID to a document without an '_id'. It
+// is written into the document itself if possible, or else a copy of the
+// document holding the ID is returned.
+func (d *Database) withGeneratedID(document interface{}) (interface{}, error) {
+	if d.IDGenerator == nil || GetDocumentMeta(document).ID != "" {
+		return document, nil
+	}
+
+	id, err := d.IDGenerator.NewID(document)
+	if err != nil {
+		return nil, err
+	}
+
+	SetDocumentMeta(document, &DocumentMeta{ID: id})
+	if GetDocumentMeta(document).ID == id {
+		return document, nil
+	}
+
+	jsonDocument, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err = json.Unmarshal(jsonDocument, &fields); err != nil {
+		return nil, err
+	}
+	if _, ok := fields["_id"]; ok && string(fields["_id"]) != `""` && string(fields["_id"]) != "null" {
+		return document, nil // the ID is held in a field we can't see
+	}
+	fields["_id"], _ = json.Marshal(id)
+
+	return fields, nil
+}
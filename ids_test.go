@@ -0,0 +1,241 @@
+package cloudant
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIDs_Random(t *testing.T) {
+	generator := RandomIDGenerator{}
+
+	first, _ := generator.NewID(nil)
+	second, _ := generator.NewID(nil)
+	if len(first) != 32 || first == second {
+		t.Errorf("unexpected IDs %s, %s", first, second)
+	}
+}
+
+func TestIDs_TimeOrdered(t *testing.T) {
+	generator := &TimeOrderedIDGenerator{}
+
+	previous := ""
+	for i := 0; i < 1000; i++ {
+		id, err := generator.NewID(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(id) != 26 || id <= previous {
+			t.Fatalf("expected increasing IDs, found %s after %s", id, previous)
+		}
+		previous = id
+	}
+}
+
+func TestIDs_Crockford(t *testing.T) {
+	id := make([]byte, 16)
+	if encodeCrockford(id) != "00000000000000000000000000" {
+		t.Errorf("unexpected encoding %s", encodeCrockford(id))
+	}
+	id[15] = 33
+	if encodeCrockford(id) != "00000000000000000000000011" {
+		t.Errorf("unexpected encoding %s", encodeCrockford(id))
+	}
+}
+
+func TestIDs_Partitioned(t *testing.T) {
+	generator := &PartitionedIDGenerator{
+		Partition: func(document interface{}) string { return document.(*cloudantDocument).Foo },
+	}
+
+	id, err := generator.NewID(&cloudantDocument{Foo: "sensor1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(id, "sensor1:") || len(id) != len("sensor1:")+32 {
+		t.Errorf("unexpected ID %s", id)
+	}
+
+	if _, err = generator.NewID(&cloudantDocument{}); err == nil {
+		t.Error("expected error for empty partition key")
+	}
+	if _, err = (&PartitionedIDGenerator{}).NewID(&cloudantDocument{}); err == nil {
+		t.Error("expected error for missing Partition function")
+	}
+}
+
+func TestIDs_WithGeneratedID(t *testing.T) {
+	database := &Database{IDGenerator: RandomIDGenerator{}}
+
+	// assigned in place
+	doc := &cloudantDocument{Foo: "mydata"}
+	body, err := database.withGeneratedID(doc)
+	if err != nil || body != doc || len(doc.ID) != 32 {
+		t.Errorf("expected ID to be set on document, found %+v (%v)", doc, err)
+	}
+
+	// existing IDs are kept
+	doc = &cloudantDocument{ID: "doc"}
+	database.withGeneratedID(doc)
+	if doc.ID != "doc" {
+		t.Errorf("expected ID to be kept, found %s", doc.ID)
+	}
+
+	// values are copied
+	body, err = database.withGeneratedID(cloudantDocument{Foo: "mydata"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(body)
+	if !strings.Contains(string(data), `"_id":"`) || strings.Contains(string(data), `"_id":""`) {
+		t.Errorf("expected ID in copy, found %s", data)
+	}
+}
+
+func TestUploader_GeneratedID(t *testing.T) {
+	var body string
+	client, server := makeMockClient(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(201)
+		w.Write([]byte(`[{"ok":true,"id":"x","rev":"1-a"}]`))
+	})
+	defer server.Close()
+
+	database, err := client.Get("db")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	database.IDGenerator = RandomIDGenerator{}
+
+	// the ID is assigned before Upload returns, not by the upload workers
+	uploader := database.Bulk(2, 0, 0)
+	doc := &cloudantDocument{Foo: "mydata"}
+	job := uploader.Upload(doc)
+	id := doc.ID
+	uploader.Flush()
+	job.Wait()
+	uploader.Stop()
+
+	if job.Error != nil || len(id) != 32 || !strings.Contains(body, `"_id":"`+id+`"`) {
+		t.Errorf("expected ID %q to be uploaded, found %s (%v)", id, body, job.Error)
+	}
+}
+
+func TestClient_UUIDsErrors(t *testing.T) {
+	client, server := makeMockClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"uuids":[]}`))
+	})
+	defer server.Close()
+
+	if _, err := client.UUIDs(-1); err == nil {
+		t.Error("expected an error for a negative number of UUIDs")
+	}
+	if _, err := client.UUIDs(1); err == nil {
+		t.Error("expected an error for an empty response")
+	}
+}
+
+func TestClient_UUIDsConcurrent(t *testing.T) {
+	var mutex sync.Mutex
+	next := 0
+	client, server := makeMockClient(func(w http.ResponseWriter, r *http.Request) {
+		count, _ := strconv.Atoi(r.URL.Query().Get("count"))
+		mutex.Lock()
+		uuids := make([]string, count)
+		for i := range uuids {
+			uuids[i] = fmt.Sprintf("%032d", next)
+			next++
+		}
+		mutex.Unlock()
+		json.NewEncoder(w).Encode(&uuidsResponse{UUIDs: uuids})
+	})
+	defer server.Close()
+
+	var wg sync.WaitGroup
+	results := make([][]string, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = client.UUIDs(5)
+		}(i)
+	}
+	wg.Wait()
+
+	seen := map[string]bool{}
+	for _, uuids := range results {
+		if len(uuids) != 5 {
+			t.Fatalf("expected 5 UUIDs, found %v", uuids)
+		}
+		for _, uuid := range uuids {
+			if seen[uuid] {
+				t.Errorf("duplicate UUID %s", uuid)
+			}
+			seen[uuid] = true
+		}
+	}
+}
+
+func TestClient_UUIDs(t *testing.T) {
+	client, err := makeClient()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	uuids, err := client.UUIDs(3)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(uuids) != 3 || uuids[0] == uuids[1] {
+		t.Errorf("unexpected UUIDs %v", uuids)
+	}
+	if len(client.uuids.uuids) != uuidPrefetchCount {
+		t.Errorf("expected %d prefetched UUIDs, found %d", uuidPrefetchCount, len(client.uuids.uuids))
+	}
+}
+
+func TestDatabase_IDGenerator(t *testing.T) {
+	database, err := makeDatabase()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer func() {
+		fmt.Printf("Deleting database %s", database.Name)
+		database.client.Delete(database.Name)
+	}()
+
+	database.IDGenerator = &TimeOrderedIDGenerator{}
+
+	first := &cloudantDocument{Foo: "first"}
+	if _, err = database.Set(first); err != nil {
+		t.Fatalf("failed to create document: %s", err)
+	}
+
+	uploader := database.Bulk(1, -1, 0)
+	second := &cloudantDocument{Foo: "second"}
+	job := uploader.UploadNow(second)
+	job.Wait()
+	uploader.Stop()
+	if job.Error != nil {
+		t.Fatalf("failed to upload document: %s", job.Error)
+	}
+
+	if len(first.ID) != 26 || first.ID >= second.ID || job.Response.ID != second.ID {
+		t.Errorf("unexpected IDs %s, %s", first.ID, second.ID)
+	}
+
+	// Note: lame attempt to close inconsistency window
+	time.Sleep(500 * time.Millisecond)
+
+	doc := &cloudantDocument{}
+	if err = database.Get(first.ID, &getQuery{}, doc); err != nil || doc.Foo != "first" {
+		t.Errorf("unexpected document %+v (%v)", doc, err)
+	}
+}
@@ -0,0 +1,78 @@
+package cloudant
+
+import (
+	"fmt"
+	"io"
+)
+
+// Limits are the sizes of documents and requests accepted by the server.
+// Writes exceeding them fail with an *ErrDocumentTooLarge before anything is
+// sent, instead of with a 413 response. Zero values disable a check, and
+// nothing is checked unless the database has Limits. Attachments streamed to
+// PutAttachment from readers of unknown length are not checked.
+type Limits struct {
+	MaxDocumentBytes int // JSON body of a single document
+	MaxRequestBytes  int // body of a request, e.g. a _bulk_docs batch or an attachment upload
+}
+
+// CloudantLimits are Cloudant's limits.
+//
+// Example:
+//
+//	limits := cloudant.CloudantLimits
+//	db.Limits = &limits
+var CloudantLimits = Limits{MaxDocumentBytes: 1 << 20, MaxRequestBytes: 10 << 20}
+
+// ErrDocumentTooLarge is returned when a document, an attachment or a request
+// is larger than the database's Limits allow.
+type ErrDocumentTooLarge struct {
+	ID    string // ID of the document, if known
+	Size  int64
+	Limit int64
+}
+
+// Error() implements the error interface
+func (e *ErrDocumentTooLarge) Error() string {
+	if e.ID == "" {
+		return fmt.Sprintf("document too large: %d bytes, limit %d", e.Size, e.Limit)
+	}
+	return fmt.Sprintf("document %s too large: %d bytes, limit %d", e.ID, e.Size, e.Limit)
+}
+
+func (d *Database) limits() Limits {
+	if d.Limits != nil {
+		return *d.Limits
+	}
+	return Limits{}
+}
+
+// checkDocumentSize validates the size of an encoded document against the
+// document limit.
+func (d *Database) checkDocumentSize(jsonDocument []byte) error {
+	limit := d.limits().MaxDocumentBytes
+	if limit <= 0 || len(jsonDocument) <= limit {
+		return nil
+	}
+
+	documentID, _ := multipartDocumentID(jsonDocument)
+	return &ErrDocumentTooLarge{ID: documentID, Size: int64(len(jsonDocument)), Limit: int64(limit)}
+}
+
+// checkRequestSize validates the size of a request body concerning documentID
+// against the request limit.
+func (d *Database) checkRequestSize(documentID string, size int64) error {
+	limit := d.limits().MaxRequestBytes
+	if limit <= 0 || size <= int64(limit) {
+		return nil
+	}
+
+	return &ErrDocumentTooLarge{ID: documentID, Size: size, Limit: int64(limit)}
+}
+
+// readerLength returns the number of bytes left in r, or -1 if unknown.
+func readerLength(r io.Reader) int64 {
+	if lenReader, ok := r.(interface{ Len() int }); ok {
+		return int64(lenReader.Len())
+	}
+	return -1
+}
@@ -0,0 +1,167 @@
+package cloudant
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLimits_CheckDocumentSize(t *testing.T) {
+	database := &Database{Limits: &Limits{MaxDocumentBytes: 20}}
+
+	if err := database.checkDocumentSize([]byte(`{"_id":"small"}`)); err != nil {
+		t.Errorf("unexpected error %s", err)
+	}
+
+	err := database.checkDocumentSize([]byte(`{"_id":"large","foo":"bar"}`))
+	tooLarge, ok := err.(*ErrDocumentTooLarge)
+	if !ok || tooLarge.ID != "large" || tooLarge.Size != 27 || tooLarge.Limit != 20 {
+		t.Errorf("unexpected error %#v", err)
+	}
+
+	database.Limits = &Limits{}
+	if err := database.checkDocumentSize([]byte(`{"_id":"large","foo":"bar"}`)); err != nil {
+		t.Errorf("unexpected error with limits disabled %s", err)
+	}
+}
+
+func TestLimits_CloudantLimits(t *testing.T) {
+	database := &Database{}
+	largeDocument := []byte(`{"foo":"` + strings.Repeat("x", 1<<20) + `"}`)
+
+	if err := database.checkDocumentSize(largeDocument); err != nil {
+		t.Errorf("unexpected error without limits %s", err)
+	}
+
+	limits := CloudantLimits
+	database.Limits = &limits
+	err := database.checkDocumentSize(largeDocument)
+	if _, ok := err.(*ErrDocumentTooLarge); !ok {
+		t.Errorf("expected *ErrDocumentTooLarge, found %v", err)
+	}
+	if err.Error() != "document too large: 1048586 bytes, limit 1048576" {
+		t.Errorf("unexpected message %s", err)
+	}
+}
+
+func TestLimits_RejectedBeforeRequest(t *testing.T) {
+	// no client: the documents must be rejected before any request is made
+	dbURL, _ := url.Parse("http://localhost:5984/limits")
+	database := &Database{URL: dbURL, Limits: &Limits{MaxDocumentBytes: 10, MaxRequestBytes: 10}}
+
+	if _, err := database.Set(map[string]string{"foo": "bar baz"}); err == nil {
+		t.Error("expected Set to fail")
+	}
+	if _, err := database.Put("doc", map[string]string{"foo": "bar baz"}, NewWriteQuery().Build()); err == nil {
+		t.Error("expected Put to fail")
+	}
+	if _, err := database.PutAttachment("doc", "", "att", "text/plain", bytes.NewReader(make([]byte, 11))); err == nil {
+		t.Error("expected PutAttachment to fail")
+	}
+
+	database.Limits.MaxDocumentBytes = 100
+	_, err := database.PutWithAttachments(map[string]string{"_id": "doc"}, []Attachment{
+		{Name: "att", ContentType: "text/plain", Body: strings.NewReader("content")},
+	})
+	if tooLarge, ok := err.(*ErrDocumentTooLarge); !ok || tooLarge.ID != "doc" {
+		t.Errorf("expected PutWithAttachments to fail, found %v", err)
+	}
+}
+
+func TestLimits_OversizeBatch(t *testing.T) {
+	client, server := makeMockClient(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL)
+	})
+	defer server.Close()
+
+	database, err := client.Get("db")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	database.Limits = &Limits{MaxRequestBytes: 20}
+
+	// the document fits no batch, even on its own
+	uploader := database.Bulk(10, 0, 0)
+	job := uploader.UploadNow(map[string]string{"foo": "bar baz"})
+	job.Wait()
+	uploader.Stop()
+
+	if tooLarge, ok := job.Error.(*ErrDocumentTooLarge); !ok || tooLarge.Limit != 20 {
+		t.Errorf("expected *ErrDocumentTooLarge, found %v", job.Error)
+	}
+}
+
+func TestLimits_BatchSeparator(t *testing.T) {
+	var mutex sync.Mutex
+	sizes := []int{}
+	client, server := makeMockClient(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		request := struct{ Docs []json.RawMessage }{}
+		json.Unmarshal(body, &request)
+		mutex.Lock()
+		sizes = append(sizes, len(body))
+		mutex.Unlock()
+
+		results := make([]string, len(request.Docs))
+		for i := range results {
+			results[i] = `{"ok":true,"id":"doc","rev":"1-a"}`
+		}
+		w.WriteHeader(201)
+		w.Write([]byte("[" + strings.Join(results, ",") + "]"))
+	})
+	defer server.Close()
+
+	database, err := client.Get("db")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	// '{"docs":[' + 100 + ',' + 100 + ']}' is 212 bytes
+	database.Limits = &Limits{MaxRequestBytes: 211}
+
+	doc := map[string]string{"_id": "doc", "foo": strings.Repeat("x", 78)}
+	if jsonDocument, _ := json.Marshal(doc); len(jsonDocument) != 100 {
+		t.Fatalf("expected a 100 byte document, found %d", len(jsonDocument))
+	}
+
+	uploader := database.Bulk(10, 0, 0)
+	jobs := []*BulkJob{uploader.Upload(doc), uploader.Upload(doc)}
+	uploader.Flush()
+	for _, job := range jobs {
+		job.Wait()
+		if job.Error != nil {
+			t.Errorf("unexpected error %s", job.Error)
+		}
+	}
+	uploader.Stop()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(sizes) != 2 || sizes[0] > 211 || sizes[1] > 211 {
+		t.Errorf("expected two batches within the limit, found %v", sizes)
+	}
+}
+
+func TestLimits_MaxBatchBytes(t *testing.T) {
+	database := &Database{Limits: &Limits{MaxRequestBytes: 1000}}
+
+	tests := []struct {
+		batchMaxBytes int
+		expected      int
+	}{
+		{0, 1000},
+		{-1, 1000},
+		{500, 500},
+		{2000, 1000},
+	}
+	for _, test := range tests {
+		uploader := &Uploader{database: database, batchMaxBytes: test.batchMaxBytes}
+		if maxBytes := uploader.maxBatchBytes(); maxBytes != test.expected {
+			t.Errorf("batchMaxBytes %d: expected %d, found %d", test.batchMaxBytes, test.expected, maxBytes)
+		}
+	}
+}
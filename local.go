@@ -0,0 +1,51 @@
+package cloudant
+
+import (
+	"net/url"
+	"strings"
+)
+
+// localPrefix is the path prefix of local (non-replicating) documents
+const localPrefix = "_local/"
+
+// GetLocal gets a local document. Local documents are not replicated and do
+// not appear in _changes or _all_docs, which makes them a good fit for
+// checkpoints. The ID may be given with or without the "_local/" prefix.
+// See: https://docs.couchdb.org/en/stable/api/local.html
+func (d *Database) GetLocal(documentID string, target interface{}) error {
+	return d.Get(localDocumentPath(documentID), &getQuery{}, target)
+}
+
+// PutLocal creates or updates a local document. To update an existing local
+// document its current revision must be given in its '_rev' attribute.
+// See: https://docs.couchdb.org/en/stable/api/local.html#put--db-_local-docid
+func (d *Database) PutLocal(documentID string, document interface{}) (*DocumentMeta, error) {
+	urlStr, err := documentURL(*d.URL, documentPath(localDocumentPath(documentID)), url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := d.write("PUT", urlStr, document, false, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result.DocumentMeta, nil
+}
+
+// DeleteLocal deletes a local document with a specified revision.
+// See: https://docs.couchdb.org/en/stable/api/local.html#delete--db-_local-docid
+func (d *Database) DeleteLocal(documentID, rev string) error {
+	return d.Delete(localDocumentPath(documentID), rev)
+}
+
+// LocalDocs returns a channel in which the local documents of the database
+// can be received. It accepts the same options as All.
+// See: https://docs.couchdb.org/en/stable/api/local.html#get--db-_local_docs
+func (d *Database) LocalDocs(args *allDocsQuery) (<-chan *AllRow, error) {
+	return d.allDocs("/_local_docs", args)
+}
+
+func localDocumentPath(documentID string) string {
+	return localPrefix + strings.TrimPrefix(documentID, localPrefix)
+}
@@ -0,0 +1,72 @@
+package cloudant
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLocal_DocumentPath(t *testing.T) {
+	for _, documentID := range []string{"checkpoint", "_local/checkpoint"} {
+		if localDocumentPath(documentID) != "_local/checkpoint" {
+			t.Errorf("unexpected path %s for %s", localDocumentPath(documentID), documentID)
+		}
+	}
+}
+
+func TestDatabase_LocalDocs(t *testing.T) {
+	database, err := makeDatabase()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer func() {
+		fmt.Printf("Deleting database %s", database.Name)
+		database.client.Delete(database.Name)
+	}()
+
+	meta, err := database.PutLocal("checkpoint", &cloudantDocument{Foo: "seq-1", Bar: 1})
+	if err != nil {
+		t.Fatalf("failed to create local document: %s", err)
+	}
+
+	doc := &struct {
+		cloudantDocument
+		Rev string `json:"_rev"`
+	}{}
+	err = database.GetLocal("checkpoint", doc)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if doc.ID != "_local/checkpoint" || doc.Foo != "seq-1" {
+		t.Errorf("unexpected local document %+v", doc)
+	}
+
+	doc.Foo = "seq-2"
+	meta, err = database.PutLocal("_local/checkpoint", doc)
+	if err != nil {
+		t.Fatalf("failed to update local document: %s", err)
+	}
+
+	rows, err := database.LocalDocs(NewAllDocsQuery().Build())
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	found := 0
+	for row := range rows {
+		if row.ID == "_local/checkpoint" {
+			found++
+		}
+	}
+	if found != 1 {
+		t.Errorf("expected local document to be listed once, found %d", found)
+	}
+
+	err = database.DeleteLocal("checkpoint", meta.Rev)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	err = database.GetLocal("checkpoint", doc)
+	if dberr, ok := err.(*CouchError); !ok || dberr.StatusCode != 404 {
+		t.Errorf("expected 404 for deleted local document, got %v", err)
+	}
+}
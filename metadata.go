@@ -0,0 +1,192 @@
+package cloudant
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// Documents may hold their metadata in string fields tagged either with the
+// JSON names `json:"_id"`/`json:"_rev"` or with `cloudant:"id"`/`cloudant:"rev"`.
+// Fields of embedded structs are also considered.
+const (
+	metaID  = "id"
+	metaRev = "rev"
+)
+
+// GetDocumentMeta reads the '_id' and '_rev' of a document, which may be a
+// struct, a pointer to a struct or a map.
+func GetDocumentMeta(document interface{}) *DocumentMeta {
+	meta := &DocumentMeta{}
+
+	switch doc := document.(type) {
+	case map[string]interface{}:
+		meta.ID, _ = doc["_id"].(string)
+		meta.Rev, _ = doc["_rev"].(string)
+	case *map[string]interface{}:
+		if doc != nil {
+			return GetDocumentMeta(*doc)
+		}
+	default:
+		if fieldName, _ := metaFieldName(document, metaID); fieldName != "" {
+			meta.ID, _ = getByFieldName(document, fieldName)
+		}
+		if fieldName, _ := metaFieldName(document, metaRev); fieldName != "" {
+			meta.Rev, _ = getByFieldName(document, fieldName)
+		}
+	}
+
+	return meta
+}
+
+// SetDocumentMeta writes the ID and revision assigned by the server back into
+// a document. Only pointers to structs and maps can be updated; empty values
+// in meta are ignored.
+func SetDocumentMeta(document interface{}, meta *DocumentMeta) {
+	if meta == nil {
+		return
+	}
+
+	switch doc := document.(type) {
+	case map[string]interface{}:
+		setMapMeta(doc, meta)
+	case *map[string]interface{}:
+		if doc != nil && *doc != nil {
+			setMapMeta(*doc, meta)
+		}
+	default:
+		if meta.ID != "" {
+			setByFieldName(document, metaID, meta.ID)
+		}
+		if meta.Rev != "" {
+			setByFieldName(document, metaRev, meta.Rev)
+		}
+	}
+}
+
+func setMapMeta(doc map[string]interface{}, meta *DocumentMeta) {
+	if meta.ID != "" {
+		doc["_id"] = meta.ID
+	}
+	if meta.Rev != "" {
+		doc["_rev"] = meta.Rev
+	}
+}
+
+// injectDocumentMeta adds the '_id' and '_rev' held in fields tagged
+// `cloudant:"id"` and `cloudant:"rev"` to an encoded struct, as json.Marshal
+// writes them under their JSON names only.
+func injectDocumentMeta(document interface{}, jsonDocument []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	for _, name := range []string{metaID, metaRev} {
+		fieldName, tagged := metaFieldName(document, name)
+		if !tagged {
+			continue
+		}
+		value, _ := getByFieldName(document, fieldName)
+		if value == "" {
+			continue
+		}
+
+		if fields == nil {
+			if err := json.Unmarshal(jsonDocument, &fields); err != nil {
+				return nil, err
+			}
+		}
+		fields["_"+name], _ = json.Marshal(value)
+	}
+
+	if fields == nil {
+		return jsonDocument, nil
+	}
+	return json.Marshal(fields)
+}
+
+// extractDocumentMeta sets the fields of target tagged `cloudant:"id"` and
+// `cloudant:"rev"` from the '_id' and '_rev' of a decoded document.
+func extractDocumentMeta(jsonDocument []byte, target interface{}) error {
+	_, idTagged := metaFieldName(target, metaID)
+	_, revTagged := metaFieldName(target, metaRev)
+	if !idTagged && !revTagged {
+		return nil
+	}
+
+	meta := &openRevsDoc{}
+	if err := json.Unmarshal(jsonDocument, meta); err != nil {
+		return err
+	}
+
+	SetDocumentMeta(target, &DocumentMeta{ID: meta.ID, Rev: meta.Rev})
+	return nil
+}
+
+// setByFieldName sets the string field holding the given metadata, if the
+// document is a pointer to a struct that has one.
+func setByFieldName(document interface{}, name, value string) {
+	fieldName, _ := metaFieldName(document, name)
+	s := reflect.ValueOf(document)
+	if fieldName == "" || s.Kind() != reflect.Ptr {
+		return
+	}
+
+	if field := s.Elem().FieldByName(fieldName); field.CanSet() {
+		field.SetString(value)
+	}
+}
+
+// metaFieldName returns the name of the string field of a struct holding the
+// given metadata, or "" if there is none. tagged is true if the field is
+// tagged `cloudant:"id"` or `cloudant:"rev"` rather than named by its JSON tag.
+func metaFieldName(document interface{}, name string) (fieldName string, tagged bool) {
+	s := reflect.ValueOf(document)
+	if s.Kind() == reflect.Ptr {
+		if s.IsNil() {
+			return "", false
+		}
+		s = s.Elem()
+	}
+
+	if s.Kind() != reflect.Struct {
+		return "", false
+	}
+
+	return findMetaFieldName(s, name)
+}
+
+func findMetaFieldName(s reflect.Value, name string) (string, bool) {
+	t := s.Type()
+
+	// explicit cloudant tags take precedence over json tags
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("cloudant") == name && t.Field(i).Type.Kind() == reflect.String {
+			return t.Field(i).Name, true
+		}
+	}
+	for i := 0; i < t.NumField(); i++ {
+		jsonName := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if jsonName == "_"+name && t.Field(i).Type.Kind() == reflect.String {
+			return t.Field(i).Name, false
+		}
+	}
+
+	// fields of embedded structs are promoted, so can be accessed by name
+	for i := 0; i < t.NumField(); i++ {
+		field := s.Field(i)
+		if !t.Field(i).Anonymous {
+			continue
+		}
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				continue
+			}
+			field = field.Elem()
+		}
+		if field.Kind() == reflect.Struct {
+			if fieldName, tagged := findMetaFieldName(field, name); fieldName != "" {
+				return fieldName, tagged
+			}
+		}
+	}
+
+	return "", false
+}
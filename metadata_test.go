@@ -0,0 +1,188 @@
+package cloudant
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+type taggedDocument struct {
+	Key     string `json:"key" cloudant:"id"`
+	Version string `json:"version" cloudant:"rev"`
+}
+
+type embeddedDocument struct {
+	*taggedDocument
+	Foo string `json:"foo"`
+}
+
+type revDocument struct {
+	cloudantDocument
+	Rev string `json:"_rev,omitempty"`
+}
+
+func TestMetadata_JSONTags(t *testing.T) {
+	doc := &revDocument{cloudantDocument: cloudantDocument{ID: "doc"}, Rev: "1-a"}
+
+	meta := GetDocumentMeta(doc)
+	if meta.ID != "doc" || meta.Rev != "1-a" {
+		t.Errorf("unexpected meta %+v", meta)
+	}
+
+	SetDocumentMeta(doc, &DocumentMeta{ID: "doc", Rev: "2-b"})
+	if doc.Rev != "2-b" {
+		t.Errorf("expected rev to be set, found %s", doc.Rev)
+	}
+
+	// values can't be updated
+	value := revDocument{}
+	SetDocumentMeta(value, &DocumentMeta{ID: "doc", Rev: "2-b"})
+	if GetDocumentMeta(value).ID != "" {
+		t.Error("expected value to be left alone")
+	}
+}
+
+func TestMetadata_CloudantTags(t *testing.T) {
+	doc := &embeddedDocument{taggedDocument: &taggedDocument{}}
+
+	SetDocumentMeta(doc, &DocumentMeta{ID: "doc", Rev: "1-a"})
+	if doc.Key != "doc" || doc.Version != "1-a" {
+		t.Errorf("unexpected document %+v", doc.taggedDocument)
+	}
+
+	meta := GetDocumentMeta(doc)
+	if meta.ID != "doc" || meta.Rev != "1-a" {
+		t.Errorf("unexpected meta %+v", meta)
+	}
+}
+
+func TestMetadata_Map(t *testing.T) {
+	doc := map[string]interface{}{"_id": "doc"}
+
+	SetDocumentMeta(doc, &DocumentMeta{Rev: "1-a"})
+	if doc["_id"] != "doc" || doc["_rev"] != "1-a" {
+		t.Errorf("unexpected document %v", doc)
+	}
+
+	meta := GetDocumentMeta(&doc)
+	if meta.ID != "doc" || meta.Rev != "1-a" {
+		t.Errorf("unexpected meta %+v", meta)
+	}
+}
+
+func TestMetadata_EncodeTagged(t *testing.T) {
+	database := &Database{}
+
+	jsonDocument, err := database.encodeDocument(&embeddedDocument{taggedDocument: &taggedDocument{Key: "doc", Version: "1-a"}}, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := map[string]string{}
+	json.Unmarshal(jsonDocument, &fields)
+	if fields["_id"] != "doc" || fields["_rev"] != "1-a" || fields["key"] != "doc" {
+		t.Errorf("expected tagged metadata to be sent, found %s", jsonDocument)
+	}
+
+	// generated IDs are written to the tagged field and sent
+	database.IDGenerator = RandomIDGenerator{}
+	doc := &taggedDocument{}
+	if jsonDocument, err = database.encodeDocument(doc, true, nil); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Key == "" || !strings.Contains(string(jsonDocument), `"_id":"`+doc.Key+`"`) {
+		t.Errorf("expected generated ID %q to be sent, found %s", doc.Key, jsonDocument)
+	}
+
+	// no metadata, nothing added
+	if jsonDocument, _ = database.encodeDocument(&embeddedDocument{Foo: "bar"}, false, nil); string(jsonDocument) != `{"foo":"bar"}` {
+		t.Errorf("unexpected document %s", jsonDocument)
+	}
+}
+
+func TestMetadata_DecodeTagged(t *testing.T) {
+	doc := &taggedDocument{}
+	err := (&Database{}).decodeDocument(strings.NewReader(`{"_id":"doc","_rev":"2-b","key":"doc","version":"1-a"}`), doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Key != "doc" || doc.Version != "2-b" {
+		t.Errorf("expected current metadata, found %+v", doc)
+	}
+}
+
+func TestDatabase_TaggedDocument(t *testing.T) {
+	database, err := makeDatabase()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer func() {
+		fmt.Printf("Deleting database %s", database.Name)
+		database.client.Delete(database.Name)
+	}()
+
+	doc := &taggedDocument{Key: "doc-tagged"}
+	meta, err := database.Set(doc)
+	if err != nil {
+		t.Fatalf("failed to create document: %s", err)
+	}
+	if meta.ID != "doc-tagged" || doc.Version != meta.Rev {
+		t.Errorf("expected tagged ID to be used, found %+v (%+v)", meta, doc)
+	}
+
+	// Note: lame attempt to close inconsistency window
+	time.Sleep(500 * time.Millisecond)
+
+	read := &taggedDocument{}
+	if err = database.Get("doc-tagged", &getQuery{}, read); err != nil {
+		t.Fatalf("failed to get document: %s", err)
+	}
+	if read.Key != "doc-tagged" || read.Version != meta.Rev {
+		t.Errorf("unexpected document %+v", read)
+	}
+
+	// the tagged rev allows an update of the document read
+	if _, err = database.Set(read); err != nil {
+		t.Fatalf("failed to update document: %s", err)
+	}
+	if !strings.HasPrefix(read.Version, "2-") {
+		t.Errorf("expected second revision, found %s", read.Version)
+	}
+}
+
+func TestDatabase_MetadataPropagation(t *testing.T) {
+	database, err := makeDatabase()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer func() {
+		fmt.Printf("Deleting database %s", database.Name)
+		database.client.Delete(database.Name)
+	}()
+
+	doc := &revDocument{cloudantDocument: cloudantDocument{Foo: "mydata"}}
+	meta, err := database.Set(doc)
+	if err != nil {
+		t.Fatalf("failed to create document: %s", err)
+	}
+	if doc.ID != meta.ID || doc.Rev != meta.Rev {
+		t.Errorf("expected %+v to be propagated, found %+v", meta, doc)
+	}
+
+	// Note: lame attempt to close inconsistency window
+	time.Sleep(500 * time.Millisecond)
+
+	// the propagated rev allows an update without copying it by hand
+	doc.Bar = 57
+	uploader := database.Bulk(1, -1, 0)
+	job := uploader.UploadNow(doc)
+	job.Wait()
+	if job.Error != nil {
+		t.Fatalf("failed to update document: %s", job.Error)
+	}
+	if doc.Rev != job.Response.Rev {
+		t.Errorf("expected rev %s to be propagated, found %s", job.Response.Rev, doc.Rev)
+	}
+	uploader.Stop()
+}
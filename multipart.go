@@ -0,0 +1,385 @@
+package cloudant
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"sort"
+)
+
+// Attachment is the content of an attachment to be uploaded with a document.
+// Length is required unless Body is a *bytes.Reader, *bytes.Buffer or
+// *strings.Reader.
+type Attachment struct {
+	Name        string
+	ContentType string
+	Length      int64
+	Body        io.Reader
+}
+
+// AttachmentInfo is an entry in a document's _attachments
+type AttachmentInfo struct {
+	ContentType   string `json:"content_type"`
+	Length        int64  `json:"length"`
+	Digest        string `json:"digest,omitempty"`
+	RevPos        int    `json:"revpos,omitempty"`
+	Encoding      string `json:"encoding,omitempty"`
+	EncodedLength int64  `json:"encoded_length,omitempty"`
+	Stub          bool   `json:"stub,omitempty"`
+	Follows       bool   `json:"follows,omitempty"`
+	Data          []byte `json:"data,omitempty"` // inline content, base64 encoded in JSON
+}
+
+// AttachmentPart is an attachment streamed from a multipart response. Its
+// content must be read before moving on to the next part.
+type AttachmentPart struct {
+	io.Reader
+	Name        string
+	ContentType string
+	Length      int64
+	Digest      string
+}
+
+// MultipartDocumentReader yields the attachments of a document fetched with
+// GetWithAttachments. Always call Close() to release the connection.
+type MultipartDocumentReader struct {
+	job       *Job
+	reader    *multipart.Reader
+	stubs     map[string]*AttachmentInfo
+	following []string // names of attachments whose content follows, in order
+	next      int
+}
+
+// PutWithAttachments writes a document together with the content of its
+// attachments in a single multipart/related request, avoiding the base64
+// inflation of inline attachments. The document must have an '_id'. Any
+// existing attachments listed in its '_attachments' are kept.
+// See: https://docs.couchdb.org/en/stable/api/document/common.html#creating-multiple-attachments
+func (d *Database) PutWithAttachments(document interface{}, attachments []Attachment) (*DocumentMeta, error) {
+	jsonDocument, err := d.multipartDocumentJSON(document, attachments)
+	if err != nil {
+		return nil, err
+	}
+
+	documentID, _ := multipartDocumentID(jsonDocument)
+	if documentID == "" {
+		return nil, fmt.Errorf("document must have an _id")
+	}
+
+	if err = d.checkDocumentSize(jsonDocument); err != nil {
+		return nil, err
+	}
+
+	size := int64(len(jsonDocument))
+	for _, attachment := range attachments {
+		if attachment.Length > 0 {
+			size += attachment.Length
+		} else {
+			size += readerLength(attachment.Body)
+		}
+	}
+	if err = d.checkRequestSize(documentID, size); err != nil {
+		return nil, err
+	}
+
+	urlStr, err := documentURL(*d.URL, documentPath(documentID), url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	// attachment parts must follow in the order of the (sorted) _attachments
+	sorted := make([]Attachment, len(attachments))
+	copy(sorted, attachments)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	pipeReader, pipeWriter := io.Pipe()
+	writer := multipart.NewWriter(pipeWriter)
+
+	go func() {
+		pipeWriter.CloseWithError(writeMultipartDocument(writer, jsonDocument, sorted))
+	}()
+
+	headers := http.Header{}
+	headers.Set("Content-Type", "multipart/related; boundary=\""+writer.Boundary()+"\"")
+
+	job, err := d.client.streamRequest("PUT", urlStr, pipeReader, headers)
+	pipeReader.Close() // unblock the writer if the request failed early
+	defer job.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	err = expectedReturnCodes(job, 201, 202)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &DocumentMeta{}
+	err = json.NewDecoder(job.response.Body).Decode(resp)
+	if err == nil {
+		SetDocumentMeta(document, resp)
+	}
+
+	return resp, err
+}
+
+func writeMultipartDocument(writer *multipart.Writer, jsonDocument []byte, attachments []Attachment) error {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", "application/json")
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	if _, err = part.Write(jsonDocument); err != nil {
+		return err
+	}
+
+	for _, attachment := range attachments {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", attachment.ContentType)
+		header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name}))
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return err
+		}
+		if _, err = io.Copy(part, attachment.Body); err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+// multipartDocumentJSON encodes a document, adding a "follows" stub to its
+// _attachments for each attachment.
+func (d *Database) multipartDocumentJSON(document interface{}, attachments []Attachment) ([]byte, error) {
+	jsonDocument, err := d.encodeDocument(document, false, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err = json.Unmarshal(jsonDocument, &fields); err != nil {
+		return nil, err
+	}
+
+	stubs := map[string]json.RawMessage{}
+	if existing, ok := fields["_attachments"]; ok && string(existing) != "null" {
+		if err = json.Unmarshal(existing, &stubs); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, attachment := range attachments {
+		length := attachment.Length
+		if length <= 0 {
+			lenReader, ok := attachment.Body.(interface{ Len() int })
+			if !ok {
+				return nil, fmt.Errorf("missing length of attachment %s", attachment.Name)
+			}
+			length = int64(lenReader.Len())
+		}
+
+		stub, err := json.Marshal(&AttachmentInfo{
+			ContentType: attachment.ContentType,
+			Length:      length,
+			Follows:     true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		stubs[attachment.Name] = stub
+	}
+
+	if fields["_attachments"], err = json.Marshal(stubs); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(fields)
+}
+
+func multipartDocumentID(jsonDocument []byte) (string, error) {
+	meta := &openRevsDoc{}
+	err := json.Unmarshal(jsonDocument, meta)
+	return meta.ID, err
+}
+
+// GetWithAttachments gets a document along with the content of its
+// attachments as a multipart/related stream. The document is decoded into
+// target, and the returned reader yields each attachment in turn.
+// See: https://docs.couchdb.org/en/stable/api/document/common.html#efficient-multiple-attachments-retrieving
+func (d *Database) GetWithAttachments(documentID string, args *getQuery, target interface{}) (*MultipartDocumentReader, error) {
+	query := *args
+	query.Attachments = true
+	params, err := query.GetQuery()
+	if err != nil {
+		return nil, err
+	}
+	urlStr, err := documentURL(*d.URL, documentPath(documentID), params)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := http.Header{}
+	headers.Set("Accept", "multipart/related")
+
+	job, err := d.client.requestWithHeaders("GET", urlStr, nil, headers)
+	if err != nil {
+		if job != nil {
+			job.Close()
+		}
+		return nil, err
+	}
+
+	err = expectedReturnCodes(job, 200)
+	if err != nil {
+		job.Close()
+		return nil, err
+	}
+
+	reader, err := d.newMultipartDocumentReader(job, target)
+	if err != nil {
+		job.Close()
+		return nil, err
+	}
+
+	return reader, nil
+}
+
+func (d *Database) newMultipartDocumentReader(job *Job, target interface{}) (*MultipartDocumentReader, error) {
+	mediaType, params, err := mime.ParseMediaType(job.response.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+
+	mdr := &MultipartDocumentReader{job: job}
+
+	var jsonDocument []byte
+	if mediaType == "multipart/related" {
+		mdr.reader = multipart.NewReader(job.response.Body, params["boundary"])
+		part, err := mdr.reader.NextPart()
+		if err != nil {
+			return nil, err
+		}
+		jsonDocument, err = readAllPart(part)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// no attachments to follow; the server sent plain JSON
+		jsonDocument, err = readAllPart(job.response.Body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	mdr.stubs, mdr.following, err = parseAttachmentStubs(jsonDocument)
+	if err != nil {
+		return nil, err
+	}
+
+	return mdr, d.decodeJSON(jsonDocument, target)
+}
+
+func readAllPart(r io.Reader) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	_, err := buf.ReadFrom(r)
+	return buf.Bytes(), err
+}
+
+// parseAttachmentStubs returns a document's _attachments along with the names
+// of those whose content follows, in document order.
+func parseAttachmentStubs(jsonDocument []byte) (map[string]*AttachmentInfo, []string, error) {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(jsonDocument, &fields); err != nil {
+		return nil, nil, err
+	}
+
+	stubs := map[string]*AttachmentInfo{}
+	following := []string{}
+
+	raw, ok := fields["_attachments"]
+	if !ok {
+		return stubs, following, nil
+	}
+	if err := json.Unmarshal(raw, &stubs); err != nil {
+		return nil, nil, err
+	}
+
+	// walk the keys in order, as the parts follow the document order
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := decoder.Token(); err != nil { // opening '{'
+		return nil, nil, err
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		name, _ := token.(string)
+		if stub := stubs[name]; stub != nil && stub.Follows {
+			following = append(following, name)
+		}
+		var skip json.RawMessage
+		if err := decoder.Decode(&skip); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return stubs, following, nil
+}
+
+// Next returns the next attachment, or io.EOF when there are no more.
+func (r *MultipartDocumentReader) Next() (*AttachmentPart, error) {
+	if r.reader == nil || r.next >= len(r.following) {
+		return nil, io.EOF
+	}
+
+	part, err := r.reader.NextPart()
+	if err != nil {
+		return nil, err
+	}
+
+	name := part.FileName()
+	if name == "" {
+		name = r.following[r.next]
+	}
+	r.next++
+
+	stub := r.stubs[name]
+	if stub == nil {
+		return nil, fmt.Errorf("unexpected attachment %s", name)
+	}
+
+	var content io.Reader = part
+	if stub.Encoding == "gzip" {
+		if content, err = gzip.NewReader(part); err != nil {
+			return nil, err
+		}
+	}
+
+	return &AttachmentPart{
+		Reader:      content,
+		Name:        name,
+		ContentType: stub.ContentType,
+		Length:      stub.Length,
+		Digest:      stub.Digest,
+	}, nil
+}
+
+// Attachments returns the document's _attachments metadata.
+func (r *MultipartDocumentReader) Attachments() map[string]*AttachmentInfo {
+	return r.stubs
+}
+
+// Close releases the underlying connection.
+func (r *MultipartDocumentReader) Close() error {
+	return r.job.response.Body.Close()
+}
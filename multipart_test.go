@@ -0,0 +1,71 @@
+package cloudant
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDatabase_PutWithAttachments(t *testing.T) {
+	database, err := makeDatabase()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer func() {
+		fmt.Printf("Deleting database %s", database.Name)
+		database.client.Delete(database.Name)
+	}()
+
+	contents := map[string][]byte{
+		"b.bin": bytes.Repeat([]byte{0, 1, 2, 3}, 1024),
+		"a.txt": []byte(strings.Repeat("hello world ", 1000)),
+	}
+
+	meta, err := database.PutWithAttachments(&cloudantDocument{ID: "doc-multipart", Foo: "mydata"}, []Attachment{
+		{Name: "b.bin", ContentType: "application/octet-stream", Body: bytes.NewReader(contents["b.bin"])},
+		{Name: "a.txt", ContentType: "text/plain", Body: bytes.NewReader(contents["a.txt"])},
+	})
+	if err != nil {
+		t.Fatalf("failed to create document: %s", err)
+	}
+	if !strings.HasPrefix(meta.Rev, "1-") {
+		t.Errorf("unexpected revision %s", meta.Rev)
+	}
+
+	// Note: lame attempt to close inconsistency window
+	time.Sleep(500 * time.Millisecond)
+
+	doc := &cloudantDocument{}
+	reader, err := database.GetWithAttachments("doc-multipart", &getQuery{}, doc)
+	if err != nil {
+		t.Fatalf("failed to get document: %s", err)
+	}
+	defer reader.Close()
+
+	if doc.Foo != "mydata" {
+		t.Errorf("unexpected document %+v", doc)
+	}
+
+	found := 0
+	for {
+		part, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read attachment: %s", err)
+		}
+		data, err := ioutil.ReadAll(part)
+		if err != nil || !bytes.Equal(data, contents[part.Name]) {
+			t.Errorf("unexpected content for attachment %s (%v)", part.Name, err)
+		}
+		found++
+	}
+	if found != len(contents) {
+		t.Errorf("expected %d attachments, found %d", len(contents), found)
+	}
+}
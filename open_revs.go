@@ -0,0 +1,255 @@
+package cloudant
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+)
+
+// Revisions is a document's _revisions: the revision history of a leaf,
+// newest first, as requested with the Revs() query option.
+type Revisions struct {
+	Start int      `json:"start"`
+	IDs   []string `json:"ids"`
+}
+
+// List returns the full revision IDs (e.g. "3-abc") of the history, newest first.
+func (r *Revisions) List() []string {
+	revs := make([]string, len(r.IDs))
+	for i, id := range r.IDs {
+		revs[i] = strconv.Itoa(r.Start-i) + "-" + id
+	}
+	return revs
+}
+
+// OpenRevision is a single revision returned by GetOpenRevs. If Missing is
+// true the server doesn't have the revision and only Rev is set. Followed
+// attachments have their content in Data.
+type OpenRevision struct {
+	Rev         string
+	Missing     bool
+	Deleted     bool
+	Doc         json.RawMessage
+	Revisions   *Revisions
+	Attachments map[string]*AttachmentInfo
+
+	db *Database
+}
+
+// Decode unmarshals the revision's document body into target, decrypting its
+// fields if the database is configured to.
+func (o *OpenRevision) Decode(target interface{}) error {
+	if o.Missing {
+		return fmt.Errorf("revision %s is missing", o.Rev)
+	}
+	if o.db == nil {
+		return json.Unmarshal(o.Doc, target)
+	}
+	return o.db.decodeJSON(o.Doc, target)
+}
+
+// openRevsRow represents an item in the JSON array returned by ?open_revs=
+type openRevsRow struct {
+	OK      json.RawMessage `json:"ok,omitempty"`
+	Missing string          `json:"missing,omitempty"`
+}
+
+// openRevsDoc holds the metadata fields of a document returned by ?open_revs=
+type openRevsDoc struct {
+	ID          string                     `json:"_id"`
+	Rev         string                     `json:"_rev"`
+	Deleted     bool                       `json:"_deleted,omitempty"`
+	Revisions   *Revisions                 `json:"_revisions,omitempty"`
+	Attachments map[string]*AttachmentInfo `json:"_attachments,omitempty"`
+}
+
+// GetOpenRevs returns the given leaf revisions of a document, or all of them
+// if revs is empty. Each requested revision is returned either with its body
+// or marked as missing. Use the Revs() query option to include the revision
+// history, and Attachments() to include attachment content.
+// See: https://docs.couchdb.org/en/stable/api/document/common.html#get--db-docid
+func (d *Database) GetOpenRevs(documentID string, revs []string, args *getQuery) ([]*OpenRevision, error) {
+	query := *args
+	query.OpenRevs = revs
+	params, err := query.GetQuery()
+	if err != nil {
+		return nil, err
+	}
+	if len(revs) == 0 {
+		params.Set("open_revs", "all")
+	}
+
+	urlStr, err := documentURL(*d.URL, documentPath(documentID), params)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := http.Header{}
+	headers.Set("Accept", "multipart/mixed, application/json")
+
+	job, err := d.client.requestWithHeaders("GET", urlStr, nil, headers)
+	defer job.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	err = expectedReturnCodes(job, 200)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaType, mediaParams, err := mime.ParseMediaType(job.response.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*OpenRevision
+	if mediaType != "multipart/mixed" {
+		results, err = decodeOpenRevsJSON(job.response.Body)
+	} else {
+		results, err = decodeOpenRevsMultipart(job.response.Body, mediaParams["boundary"])
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		result.db = d
+	}
+	return results, nil
+}
+
+func decodeOpenRevsMultipart(r io.Reader, boundary string) ([]*OpenRevision, error) {
+	results := []*OpenRevision{}
+	reader := multipart.NewReader(r, boundary)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return results, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := decodeOpenRevsPart(part)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+}
+
+func decodeOpenRevsJSON(r io.Reader) ([]*OpenRevision, error) {
+	rows := []openRevsRow{}
+	if err := json.NewDecoder(r).Decode(&rows); err != nil {
+		return nil, err
+	}
+
+	results := make([]*OpenRevision, 0, len(rows))
+	for _, row := range rows {
+		if row.Missing != "" {
+			results = append(results, &OpenRevision{Rev: row.Missing, Missing: true})
+			continue
+		}
+		result, err := newOpenRevision(row.OK)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// decodeOpenRevsPart decodes a part of a multipart/mixed open_revs response,
+// which is either a JSON document (or missing marker), or a multipart/related
+// document followed by its attachments.
+func decodeOpenRevsPart(part *multipart.Part) (*OpenRevision, error) {
+	mediaType, params, err := mime.ParseMediaType(part.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+
+	if mediaType != "multipart/related" {
+		data, err := ioutil.ReadAll(part)
+		if err != nil {
+			return nil, err
+		}
+
+		missing := &openRevsRow{}
+		if err = json.Unmarshal(data, missing); err == nil && missing.Missing != "" {
+			return &OpenRevision{Rev: missing.Missing, Missing: true}, nil
+		}
+		return newOpenRevision(data)
+	}
+
+	related := multipart.NewReader(part, params["boundary"])
+	docPart, err := related.NextPart()
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(docPart)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := newOpenRevision(data)
+	if err != nil {
+		return nil, err
+	}
+
+	_, following, err := parseAttachmentStubs(data)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range following {
+		attPart, err := related.NextPart()
+		if err != nil {
+			return nil, err
+		}
+		if attPart.FileName() != "" {
+			name = attPart.FileName()
+		}
+
+		att := result.Attachments[name]
+		if att == nil {
+			return nil, fmt.Errorf("unexpected attachment %s", name)
+		}
+
+		var content io.Reader = attPart
+		if att.Encoding == "gzip" {
+			if content, err = gzip.NewReader(attPart); err != nil {
+				return nil, err
+			}
+		}
+		if att.Data, err = ioutil.ReadAll(content); err != nil {
+			return nil, err
+		}
+		att.Follows = false
+	}
+
+	return result, nil
+}
+
+func newOpenRevision(data []byte) (*OpenRevision, error) {
+	meta := &openRevsDoc{}
+	if err := json.Unmarshal(data, meta); err != nil {
+		return nil, err
+	}
+
+	return &OpenRevision{
+		Rev:         meta.Rev,
+		Deleted:     meta.Deleted,
+		Doc:         json.RawMessage(bytes.TrimSpace(data)),
+		Revisions:   meta.Revisions,
+		Attachments: meta.Attachments,
+	}, nil
+}
@@ -0,0 +1,85 @@
+package cloudant
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRevisions_List(t *testing.T) {
+	revisions := &Revisions{Start: 3, IDs: []string{"ccc", "bbb", "aaa"}}
+
+	revs := revisions.List()
+	if strings.Join(revs, ",") != "3-ccc,2-bbb,1-aaa" {
+		t.Errorf("unexpected revision list %v", revs)
+	}
+}
+
+func TestOpenRevs_DecodeJSON(t *testing.T) {
+	data := `[{"ok":{"_id":"doc","_rev":"2-bbb","_deleted":true,"_revisions":{"start":2,"ids":["bbb","aaa"]}}},{"missing":"1-zzz"}]`
+
+	results, err := decodeOpenRevsJSON(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("unexpected number of revisions %d", len(results))
+	}
+	if results[0].Rev != "2-bbb" || !results[0].Deleted || len(results[0].Revisions.IDs) != 2 {
+		t.Errorf("unexpected revision %+v", results[0])
+	}
+	if !results[1].Missing || results[1].Rev != "1-zzz" {
+		t.Errorf("unexpected missing revision %+v", results[1])
+	}
+	if err = results[1].Decode(&struct{}{}); err == nil {
+		t.Error("expected error decoding a missing revision")
+	}
+}
+
+func TestDatabase_GetOpenRevs(t *testing.T) {
+	database, err := makeDatabase()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer func() {
+		fmt.Printf("Deleting database %s", database.Name)
+		database.client.Delete(database.Name)
+	}()
+
+	meta, err := database.Set(&cloudantDocument{ID: "doc-open-revs", Foo: "mydata", Bar: 57})
+	if err != nil {
+		t.Fatalf("failed to create document: %s", err)
+	}
+
+	// Note: lame attempt to close inconsistency window
+	time.Sleep(500 * time.Millisecond)
+
+	missingRev := "1-00000000000000000000000000000000"
+	results, err := database.GetOpenRevs("doc-open-revs", []string{meta.Rev, missingRev}, NewGetQuery().Revs().Build())
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("unexpected number of revisions %d", len(results))
+	}
+
+	for _, result := range results {
+		switch result.Rev {
+		case meta.Rev:
+			doc := &cloudantDocument{}
+			if err = result.Decode(doc); err != nil || doc.Foo != "mydata" {
+				t.Errorf("unexpected document %+v (%v)", doc, err)
+			}
+			if result.Revisions == nil || result.Revisions.List()[0] != meta.Rev {
+				t.Errorf("unexpected revision history %+v", result.Revisions)
+			}
+		case missingRev:
+			if !result.Missing {
+				t.Error("expected revision to be missing")
+			}
+		default:
+			t.Errorf("unexpected revision %s", result.Rev)
+		}
+	}
+}
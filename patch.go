@@ -0,0 +1,389 @@
+package cloudant
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Patch is a change applied to the JSON body of a document by Database.Patch
+type Patch interface {
+	Apply(document []byte) ([]byte, error)
+}
+
+// MergePatch is an RFC 7386 JSON Merge Patch: its fields replace those of the
+// document, nested objects are merged and nil values remove fields.
+//
+// Example:
+//
+//	patch := cloudant.MergePatch{"status": "done", "draft": nil}
+type MergePatch map[string]interface{}
+
+// JSONPatch is an RFC 6902 JSON Patch, a list of operations applied in order.
+// If a "test" operation fails the whole patch fails with a *PatchTestError.
+//
+// Example:
+//
+//	patch := cloudant.JSONPatch{
+//		{Op: "test", Path: "/status", Value: "pending"},
+//		{Op: "replace", Path: "/status", Value: "done"},
+//		{Op: "add", Path: "/tags/-", Value: "reviewed"},
+//	}
+type JSONPatch []PatchOperation
+
+// PatchOperation is an operation of a JSONPatch. Op is one of "add",
+// "remove", "replace", "move", "copy" or "test". Paths are JSON Pointers
+// (RFC 6901), e.g. "/address/city".
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	From  string      `json:"from,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface. The value of "add",
+// "replace" and "test" operations is always written, even if nil, as RFC
+// 6902 requires it.
+func (op PatchOperation) MarshalJSON() ([]byte, error) {
+	type operation PatchOperation
+
+	switch op.Op {
+	case "add", "replace", "test":
+		return json.Marshal(&struct {
+			operation
+			Value interface{} `json:"value"`
+		}{operation(op), op.Value})
+	default:
+		return json.Marshal(operation(op))
+	}
+}
+
+// PatchTestError is returned when a "test" operation of a JSONPatch fails
+type PatchTestError struct {
+	Path string
+}
+
+// Error() implements the error interface
+func (e *PatchTestError) Error() string {
+	return fmt.Sprintf("patch test failed at %s", e.Path)
+}
+
+// Patch applies a patch to the current revision of a document and saves it,
+// re-reading the document and re-applying the patch on conflicts as allowed
+// by the database's UpdatePolicy.
+func (d *Database) Patch(documentID string, patch Patch) (*DocumentMeta, error) {
+	doc := json.RawMessage{}
+	return d.Update(documentID, &doc, func(interface{}) error {
+		patched, err := patch.Apply(doc)
+		if err != nil {
+			return err
+		}
+		doc = patched
+		return nil
+	})
+}
+
+// Apply implements the Patch interface.
+func (p MergePatch) Apply(document []byte) ([]byte, error) {
+	target, err := decodePatchJSON(document)
+	if err != nil {
+		return nil, err
+	}
+
+	patch, err := normalizePatchValue(map[string]interface{}(p))
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(mergePatch(target, patch))
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+		} else {
+			targetObject[key] = mergePatch(targetObject[key], value)
+		}
+	}
+
+	return targetObject
+}
+
+// Apply implements the Patch interface.
+func (p JSONPatch) Apply(document []byte) ([]byte, error) {
+	doc, err := decodePatchJSON(document)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, op := range p {
+		if doc, err = op.apply(doc); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(doc)
+}
+
+func (op *PatchOperation) apply(doc interface{}) (interface{}, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		value, err := normalizePatchValue(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "test" {
+			current, err := pointerGet(doc, path)
+			if err != nil || !jsonEqual(current, value) {
+				return nil, &PatchTestError{Path: op.Path}
+			}
+			return doc, nil
+		}
+		return pointerSet(doc, path, value, op.Op == "add")
+	case "remove":
+		doc, _, err = pointerRemove(doc, path)
+		return doc, err
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		var value interface{}
+		if op.Op == "move" {
+			if len(path) > len(from) && strings.HasPrefix(op.Path, op.From+"/") {
+				return nil, fmt.Errorf("cannot move %s into itself", op.From)
+			}
+			doc, value, err = pointerRemove(doc, from)
+		} else {
+			if value, err = pointerGet(doc, from); err == nil {
+				value, err = normalizePatchValue(value) // deep copy
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		return pointerSet(doc, path, value, true)
+	default:
+		return nil, fmt.Errorf("unknown patch operation %q", op.Op)
+	}
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into its unescaped tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+func pointerGet(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("path not found: %s", token)
+			}
+			doc = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[index]
+		default:
+			return nil, fmt.Errorf("path not found: %s", token)
+		}
+	}
+	return doc, nil
+}
+
+// pointerSet adds (insert true) or replaces a value, returning the new root
+func pointerSet(doc interface{}, path []string, value interface{}, insert bool) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return pointerUpdate(doc, path, func(parent interface{}, token string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := node[token]; !ok && !insert {
+				return nil, fmt.Errorf("path not found: %s", token)
+			}
+			node[token] = value
+			return node, nil
+		case []interface{}:
+			if !insert {
+				index, err := arrayIndex(token, len(node)-1)
+				if err != nil {
+					return nil, err
+				}
+				node[index] = value
+				return node, nil
+			}
+			if token == "-" {
+				return append(node, value), nil
+			}
+			index, err := arrayIndex(token, len(node))
+			if err != nil {
+				return nil, err
+			}
+			node = append(node, nil)
+			copy(node[index+1:], node[index:])
+			node[index] = value
+			return node, nil
+		default:
+			return nil, fmt.Errorf("path not found: %s", token)
+		}
+	})
+}
+
+// pointerRemove removes a value, returning the new root and the removed value
+func pointerRemove(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("cannot remove the whole document")
+	}
+
+	var removed interface{}
+	doc, err := pointerUpdate(doc, path, func(parent interface{}, token string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("path not found: %s", token)
+			}
+			removed = value
+			delete(node, token)
+			return node, nil
+		case []interface{}:
+			index, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			removed = node[index]
+			return append(node[:index], node[index+1:]...), nil
+		default:
+			return nil, fmt.Errorf("path not found: %s", token)
+		}
+	})
+	return doc, removed, err
+}
+
+// pointerUpdate walks down to the parent of the last token of path and
+// replaces it with the result of update, rebuilding the containers above it.
+func pointerUpdate(doc interface{}, path []string, update func(parent interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return update(doc, path[0])
+	}
+
+	child, err := pointerGet(doc, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	child, err = pointerUpdate(child, path[1:], update)
+	if err != nil {
+		return nil, err
+	}
+
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		node[path[0]] = child
+	case []interface{}:
+		index, _ := arrayIndex(path[0], len(node)-1)
+		node[index] = child
+	}
+	return doc, nil
+}
+
+func arrayIndex(token string, max int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > max || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %s", token)
+	}
+	return index, nil
+}
+
+func decodePatchJSON(data []byte) (interface{}, error) {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err := decoder.Decode(&value)
+	return value, err
+}
+
+// normalizePatchValue converts a Go value into a fresh tree of the types
+// produced by decodePatchJSON
+func normalizePatchValue(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return decodePatchJSON(data)
+}
+
+// jsonEqual compares decoded JSON values, treating numbers as equal if their
+// values are.
+func jsonEqual(a, b interface{}) bool {
+	switch aValue := a.(type) {
+	case json.Number:
+		bValue, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		aFloat, aErr := aValue.Float64()
+		bFloat, bErr := bValue.Float64()
+		return aErr == nil && bErr == nil && aFloat == bFloat
+	case map[string]interface{}:
+		bValue, ok := b.(map[string]interface{})
+		if !ok || len(aValue) != len(bValue) {
+			return false
+		}
+		for key, value := range aValue {
+			other, ok := bValue[key]
+			if !ok || !jsonEqual(value, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		bValue, ok := b.([]interface{})
+		if !ok || len(aValue) != len(bValue) {
+			return false
+		}
+		for i := range aValue {
+			if !jsonEqual(aValue[i], bValue[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
@@ -0,0 +1,152 @@
+package cloudant
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPatch_MergePatch(t *testing.T) {
+	// from RFC 7386, section 3
+	document := `{"title":"Goodbye!","author":{"givenName":"John","familyName":"Doe"},"tags":["example","sample"],"content":"This will be unchanged"}`
+	patch := MergePatch{
+		"title":       "Hello!",
+		"phoneNumber": "+01-123-456-7890",
+		"author":      map[string]interface{}{"familyName": nil},
+		"tags":        []string{"example"},
+	}
+	expected := `{"author":{"givenName":"John"},"content":"This will be unchanged","phoneNumber":"+01-123-456-7890","tags":["example"],"title":"Hello!"}`
+
+	patched, err := patch.Apply([]byte(document))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(patched) != expected {
+		t.Errorf("Expected %s, found %s", expected, patched)
+	}
+}
+
+func TestPatch_JSONPatch(t *testing.T) {
+	document := `{"foo":{"bar":"baz","n":1},"list":["a","b"]}`
+
+	tests := []struct {
+		patch    JSONPatch
+		expected string
+	}{
+		{JSONPatch{{Op: "add", Path: "/baz", Value: "qux"}}, `{"baz":"qux","foo":{"bar":"baz","n":1},"list":["a","b"]}`},
+		{JSONPatch{{Op: "add", Path: "/list/1", Value: "x"}}, `{"foo":{"bar":"baz","n":1},"list":["a","x","b"]}`},
+		{JSONPatch{{Op: "add", Path: "/list/-", Value: "c"}}, `{"foo":{"bar":"baz","n":1},"list":["a","b","c"]}`},
+		{JSONPatch{{Op: "remove", Path: "/list/0"}}, `{"foo":{"bar":"baz","n":1},"list":["b"]}`},
+		{JSONPatch{{Op: "replace", Path: "/foo/bar", Value: 42}}, `{"foo":{"bar":42,"n":1},"list":["a","b"]}`},
+		{JSONPatch{{Op: "move", From: "/foo/bar", Path: "/bar"}}, `{"bar":"baz","foo":{"n":1},"list":["a","b"]}`},
+		{JSONPatch{{Op: "copy", From: "/list", Path: "/foo/list"}}, `{"foo":{"bar":"baz","list":["a","b"],"n":1},"list":["a","b"]}`},
+		{JSONPatch{{Op: "test", Path: "/foo/n", Value: 1.0}, {Op: "remove", Path: "/foo"}}, `{"list":["a","b"]}`},
+	}
+
+	for _, test := range tests {
+		patched, err := test.patch.Apply([]byte(document))
+		if err != nil {
+			t.Errorf("%+v: %s", test.patch, err)
+			continue
+		}
+		if string(patched) != test.expected {
+			t.Errorf("Expected %s, found %s", test.expected, patched)
+		}
+	}
+}
+
+func TestPatch_JSONPatchErrors(t *testing.T) {
+	document := `{"foo":{"bar":"baz"},"list":["a"]}`
+
+	_, err := JSONPatch{{Op: "test", Path: "/foo/bar", Value: "qux"}}.Apply([]byte(document))
+	if testErr, ok := err.(*PatchTestError); !ok || testErr.Path != "/foo/bar" {
+		t.Errorf("expected PatchTestError, got %v", err)
+	}
+
+	for _, patch := range []JSONPatch{
+		{{Op: "replace", Path: "/missing", Value: 1}},
+		{{Op: "remove", Path: "/list/1"}},
+		{{Op: "add", Path: "/list/01", Value: 1}},
+		{{Op: "move", From: "/foo", Path: "/foo/bar/x"}},
+		{{Op: "invalid", Path: "/foo"}},
+		{{Op: "add", Path: "foo", Value: 1}},
+	} {
+		if _, err = patch.Apply([]byte(document)); err == nil {
+			t.Errorf("expected error applying %+v", patch)
+		}
+	}
+}
+
+func TestPatch_MarshalNullValue(t *testing.T) {
+	patch := JSONPatch{
+		{Op: "replace", Path: "/x", Value: nil},
+		{Op: "remove", Path: "/y"},
+	}
+
+	data, err := json.Marshal(patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `[{"op":"replace","path":"/x","value":null},{"op":"remove","path":"/y"}]`
+	if string(data) != expected {
+		t.Errorf("Expected %s, found %s", expected, data)
+	}
+}
+
+func TestPatch_Pointer(t *testing.T) {
+	tokens, err := parsePointer("/a~1b/c~0d/~01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(tokens)
+	if string(data) != `["a/b","c~d","~1"]` {
+		t.Errorf("unexpected tokens %s", data)
+	}
+}
+
+func TestDatabase_Patch(t *testing.T) {
+	database, err := makeDatabase()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer func() {
+		fmt.Printf("Deleting database %s", database.Name)
+		database.client.Delete(database.Name)
+	}()
+
+	_, err = database.Set(&cloudantDocument{ID: "doc-patch", Foo: "mydata", Bar: 57})
+	if err != nil {
+		t.Fatalf("failed to create document: %s", err)
+	}
+
+	// Note: lame attempt to close inconsistency window
+	time.Sleep(500 * time.Millisecond)
+
+	_, err = database.Patch("doc-patch", MergePatch{"foo": "merged"})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	_, err = database.Patch("doc-patch", JSONPatch{
+		{Op: "test", Path: "/foo", Value: "merged"},
+		{Op: "replace", Path: "/bar", Value: 58},
+	})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	_, err = database.Patch("doc-patch", JSONPatch{{Op: "test", Path: "/bar", Value: 57}})
+	if _, ok := err.(*PatchTestError); !ok {
+		t.Errorf("expected PatchTestError, got %v", err)
+	}
+
+	doc := &cloudantDocument{}
+	err = database.Get("doc-patch", &getQuery{}, doc)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if doc.Foo != "merged" || doc.Bar != 58 {
+		t.Errorf("unexpected patched document %+v", doc)
+	}
+}
@@ -33,6 +33,10 @@ type Job struct {
 	error      error
 	isDone     chan bool
 	isLogin    bool
+	streamBody bool // send the request body as-is, without buffering it for retries
+	noRetry    bool // return the first response or error, e.g. for health probes
+	renewOnce  bool // renew the session at most once on 401 and 403 responses
+	renewed    bool // the session has been renewed for this job
 }
 
 // Convenience function to check a response for errors
@@ -118,22 +122,33 @@ func (w *worker) start() {
 			LogFunc("Request (attempt: %d) %s %s", job.retryCount, job.request.Method,
 				job.request.URL.String())
 
-			// save body for retries
-			if job.retryCount == 0 && job.request.Body != nil {
+			if !job.streamBody {
+				// save body for retries
+				if job.retryCount == 0 && job.request.Body != nil {
+					var err error
+					job.bodyBytes, err = ioutil.ReadAll(job.request.Body)
+					if err != nil {
+						LogFunc("failed to read request body, %s", err)
+					}
+				}
+
+				job.request.Body = ioutil.NopCloser(bytes.NewReader(job.bodyBytes))
+			} else if job.retryCount > 0 && job.request.GetBody != nil {
+				// rewind a streamed body that can be read again
 				var err error
-				job.bodyBytes, err = ioutil.ReadAll(job.request.Body)
+				job.request.Body, err = job.request.GetBody()
 				if err != nil {
-					LogFunc("failed to read request body, %s", err)
+					LogFunc("failed to rewind request body, %s", err)
 				}
 			}
 
-			job.request.Body = ioutil.NopCloser(bytes.NewReader(job.bodyBytes))
-
 			// add go-cloudant UA
 			job.request.Header.Add("User-Agent", "go-cloudant/"+VERSION+"/"+runtime.Version())
 
 			resp, err := worker.client.httpClient.Do(job.request)
 
+			renewSession := !job.isLogin && !job.noRetry && !(job.renewOnce && job.renewed)
+
 			var retry bool
 			if err != nil {
 				LogFunc("failed to submit request, %s", err)
@@ -141,19 +156,24 @@ func (w *worker) start() {
 			} else {
 				switch resp.StatusCode {
 				case 401:
-					if !job.isLogin {
+					if renewSession {
 						LogFunc("renewing session")
-						w.client.LogIn()
+						worker.client.LogIn()
+						job.renewed = true
 						retry = true
 					}
 				case 403:
+					if !renewSession {
+						break
+					}
 					response := &CredentialsExpiredResponse{}
 					err = json.NewDecoder(resp.Body).Decode(response)
 
 					retry = false
 					if err == nil && response.Error == "credentials_expired" {
 						LogFunc("renewing session")
-						w.client.LogIn()
+						worker.client.LogIn()
+						job.renewed = true
 						retry = true
 					}
 				case 429:
@@ -165,14 +185,25 @@ func (w *worker) start() {
 				}
 			}
 
+			if job.noRetry {
+				retry = false
+			}
+
+			if retry && job.streamBody && job.request.Body != nil && job.request.GetBody == nil {
+				// a streamed body has been consumed and can't be sent again
+				LogFunc("%s %s failed, streamed requests are not retried",
+					job.request.Method, job.request.URL.String())
+				retry = false
+			}
+
 			if retry {
-				if job.retryCount < w.client.retryCountMax {
+				if job.retryCount < worker.client.retryCountMax {
 					job.retryCount += 1
 
 					go func(startDelay int) {
 						time.Sleep(time.Duration(startDelay) * time.Second)
-						w.client.Execute(job)
-					}(random(w.client.retryDelayMin, w.client.retryDelayMax))
+						worker.client.Execute(job)
+					}(random(worker.client.retryDelayMin, worker.client.retryDelayMax))
 
 					return
 				} else {
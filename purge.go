@@ -0,0 +1,97 @@
+package cloudant
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+)
+
+// PurgeResult is the response from the _purge endpoint. Purged maps each
+// document ID to the revisions that were actually purged.
+type PurgeResult struct {
+	Purged map[string][]string `json:"purged"`
+}
+
+// Purge permanently removes the given revisions of documents, keyed by
+// document ID. Unlike Delete, no tombstone is left behind and the purge is
+// not replicated.
+// See: https://docs.couchdb.org/en/stable/api/database/misc.html#db-purge
+func (d *Database) Purge(revs map[string][]string) (*PurgeResult, error) {
+	body, err := json.Marshal(revs)
+	if err != nil {
+		return nil, err
+	}
+
+	urlStr, err := Endpoint(*d.URL, "/_purge", url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := d.client.request("POST", urlStr, bytes.NewReader(body))
+	defer job.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	err = expectedReturnCodes(job, 200, 201, 202)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PurgeResult{}
+	err = json.NewDecoder(job.response.Body).Decode(result)
+
+	return result, err
+}
+
+// PurgeDocuments permanently removes every leaf revision of the given
+// documents, including conflicting and deleted leaves.
+func (d *Database) PurgeDocuments(documentIDs ...string) (*PurgeResult, error) {
+	revs := map[string][]string{}
+	for _, documentID := range documentIDs {
+		leaves, err := d.LeafRevisions(documentID)
+		if err != nil {
+			return nil, err
+		}
+		if len(leaves) > 0 {
+			revs[documentID] = leaves
+		}
+	}
+
+	if len(revs) == 0 {
+		return &PurgeResult{Purged: map[string][]string{}}, nil
+	}
+
+	return d.Purge(revs)
+}
+
+// LeafRevisions returns every leaf revision of a document: the winning
+// revision, any conflicts, and any deleted leaves.
+func (d *Database) LeafRevisions(documentID string) ([]string, error) {
+	openRevs, err := d.GetOpenRevs(documentID, nil, &getQuery{})
+	if err != nil {
+		return nil, err
+	}
+
+	revs := make([]string, 0, len(openRevs))
+	for _, openRev := range openRevs {
+		if !openRev.Missing {
+			revs = append(revs, openRev.Rev)
+		}
+	}
+
+	return revs, nil
+}
+
+// PurgedInfosLimit returns the number of purge requests the database keeps
+// a history of.
+// See: https://docs.couchdb.org/en/stable/api/database/misc.html#db-purged-infos-limit
+func (d *Database) PurgedInfosLimit() (int, error) {
+	return d.getLimit("/_purged_infos_limit")
+}
+
+// SetPurgedInfosLimit sets the number of purge requests the database keeps a
+// history of.
+func (d *Database) SetPurgedInfosLimit(limit int) error {
+	return d.setLimit("/_purged_infos_limit", limit)
+}
@@ -0,0 +1,85 @@
+package cloudant
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestDatabase_PurgeDocuments(t *testing.T) {
+	if travis() {
+		fmt.Printf("[SKIP] TestDatabase_PurgeDocuments requires CouchDB 2.3+")
+		return
+	}
+	database, err := makeDatabase()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer func() {
+		fmt.Printf("Deleting database %s", database.Name)
+		database.client.Delete(database.Name)
+	}()
+
+	// create a conflict with new_edits=false
+	uploader := database.Bulk(2, -1, 0)
+	uploader.NewEdits = false
+	for _, rev := range []string{"1-aaaa", "1-bbbb"} {
+		uploader.Upload(struct {
+			ID  string `json:"_id"`
+			Rev string `json:"_rev"`
+		}{"doc-purge", rev})
+	}
+	uploader.Flush()
+
+	// Note: lame attempt to close inconsistency window
+	time.Sleep(500 * time.Millisecond)
+
+	leaves, err := database.LeafRevisions("doc-purge")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(leaves) != 2 {
+		t.Fatalf("expected 2 leaf revisions, found %v", leaves)
+	}
+
+	result, err := database.PurgeDocuments("doc-purge")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(result.Purged["doc-purge"]) != 2 {
+		t.Errorf("unexpected purged revisions %v", result.Purged)
+	}
+
+	err = database.Get("doc-purge", &getQuery{}, &struct{}{})
+	if dberr, ok := err.(*CouchError); !ok || dberr.StatusCode != 404 {
+		t.Errorf("expected 404 for purged document, got %v", err)
+	}
+}
+
+func TestDatabase_PurgedInfosLimit(t *testing.T) {
+	if travis() {
+		fmt.Printf("[SKIP] TestDatabase_PurgedInfosLimit requires CouchDB 2.3+")
+		return
+	}
+	database, err := makeDatabase()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer func() {
+		fmt.Printf("Deleting database %s", database.Name)
+		database.client.Delete(database.Name)
+	}()
+
+	err = database.SetPurgedInfosLimit(500)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	limit, err := database.PurgedInfosLimit()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if limit != 500 {
+		t.Errorf("unexpected purged infos limit %d", limit)
+	}
+}
@@ -0,0 +1,221 @@
+package cloudant
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"time"
+)
+
+// ReshardState is the state of resharding on the cluster or of a single job
+type ReshardState string
+
+// Constants defining the possible resharding states
+const (
+	ReshardRunning   ReshardState = "running"
+	ReshardStopped   ReshardState = "stopped"
+	ReshardNew       ReshardState = "new"
+	ReshardCompleted ReshardState = "completed"
+	ReshardFailed    ReshardState = "failed"
+)
+
+// ReshardSummary is the response from the /_reshard endpoint
+type ReshardSummary struct {
+	State       ReshardState `json:"state"`
+	StateReason string       `json:"state_reason"`
+	Completed   int          `json:"completed"`
+	Failed      int          `json:"failed"`
+	Running     int          `json:"running"`
+	Stopped     int          `json:"stopped"`
+	Total       int          `json:"total"`
+}
+
+// ReshardStateInfo is the body of the /_reshard/state and
+// /_reshard/jobs/{id}/state endpoints
+type ReshardStateInfo struct {
+	State  ReshardState `json:"state"`
+	Reason string       `json:"reason,omitempty"`
+}
+
+// ReshardHistoryEvent is a state transition of a resharding job
+type ReshardHistoryEvent struct {
+	Type      string    `json:"type"`
+	Detail    string    `json:"detail"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ReshardJob is a shard splitting job
+type ReshardJob struct {
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	JobState   ReshardState           `json:"job_state"`
+	SplitState string                 `json:"split_state"`
+	Node       string                 `json:"node"`
+	Source     string                 `json:"source"`
+	Target     []string               `json:"target"`
+	StartTime  time.Time              `json:"start_time"`
+	UpdateTime time.Time              `json:"update_time"`
+	StateInfo  map[string]interface{} `json:"state_info"`
+	History    []ReshardHistoryEvent  `json:"history"`
+}
+
+// ReshardJobs is the response from the /_reshard/jobs endpoint
+type ReshardJobs struct {
+	Jobs      []ReshardJob `json:"jobs"`
+	Offset    int          `json:"offset"`
+	TotalRows int          `json:"total_rows"`
+}
+
+// ReshardJobRequest is the body of a request to create resharding jobs.
+// Either Shard, or DB optionally narrowed by Node and Range, must be set.
+type ReshardJobRequest struct {
+	Type  string `json:"type"` // only "split" is supported
+	DB    string `json:"db,omitempty"`
+	Node  string `json:"node,omitempty"`
+	Range string `json:"range,omitempty"`
+	Shard string `json:"shard,omitempty"`
+}
+
+// ReshardJobResponse is the outcome of creating a single resharding job
+type ReshardJobResponse struct {
+	OK     bool   `json:"ok"`
+	ID     string `json:"id"`
+	Node   string `json:"node"`
+	Shard  string `json:"shard"`
+	Error  string `json:"error,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Reshard returns a summary of resharding on the cluster.
+// See: https://docs.couchdb.org/en/stable/api/server/common.html#reshard
+func (c *CouchClient) Reshard() (*ReshardSummary, error) {
+	summary := &ReshardSummary{}
+	err := c.getJSON("/_reshard", url.Values{}, summary)
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// ReshardState returns whether resharding is running or stopped on the cluster.
+func (c *CouchClient) ReshardState() (*ReshardStateInfo, error) {
+	state := &ReshardStateInfo{}
+	err := c.getJSON("/_reshard/state", url.Values{}, state)
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// SetReshardState starts (ReshardRunning) or stops (ReshardStopped)
+// resharding on the whole cluster.
+func (c *CouchClient) SetReshardState(state ReshardState, reason string) error {
+	return c.putReshardState("_reshard/state", state, reason)
+}
+
+// ReshardJobs returns all resharding jobs.
+func (c *CouchClient) ReshardJobs() (*ReshardJobs, error) {
+	jobs := &ReshardJobs{}
+	err := c.getJSON("/_reshard/jobs", url.Values{}, jobs)
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// ReshardJob returns a single resharding job.
+func (c *CouchClient) ReshardJob(jobID string) (*ReshardJob, error) {
+	urlStr, err := documentURL(*c.rootURL, reshardJobPath(jobID), url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	job := &ReshardJob{}
+	err = c.getURLJSON(urlStr, job)
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// CreateReshardJob creates one or more resharding jobs. A job is created for
+// every shard copy matched by the request.
+func (c *CouchClient) CreateReshardJob(req *ReshardJobRequest) ([]ReshardJobResponse, error) {
+	if req.Type == "" {
+		req.Type = "split"
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	urlStr, err := Endpoint(*c.rootURL, "/_reshard/jobs", url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := c.request("POST", urlStr, bytes.NewReader(body))
+	defer job.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	err = expectedReturnCodes(job, 201)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []ReshardJobResponse{}
+	err = json.NewDecoder(job.response.Body).Decode(&responses)
+
+	return responses, err
+}
+
+// SetReshardJobState resumes (ReshardRunning) or stops (ReshardStopped) a
+// single resharding job.
+func (c *CouchClient) SetReshardJobState(jobID string, state ReshardState, reason string) error {
+	return c.putReshardState(reshardJobPath(jobID)+"/state", state, reason)
+}
+
+// DeleteReshardJob stops and removes a resharding job.
+func (c *CouchClient) DeleteReshardJob(jobID string) error {
+	urlStr, err := documentURL(*c.rootURL, reshardJobPath(jobID), url.Values{})
+	if err != nil {
+		return err
+	}
+
+	job, err := c.request("DELETE", urlStr, nil)
+	defer job.Close()
+	if err != nil {
+		return err
+	}
+
+	return expectedReturnCodes(job, 200)
+}
+
+// reshardJobPath returns the escaped URL path of a resharding job.
+func reshardJobPath(jobID string) string {
+	return "_reshard/jobs/" + escapePathSegment(jobID)
+}
+
+// putReshardState sets the state at escapedPath, as escaped by documentURL.
+func (c *CouchClient) putReshardState(escapedPath string, state ReshardState, reason string) error {
+	body, err := json.Marshal(&ReshardStateInfo{State: state, Reason: reason})
+	if err != nil {
+		return err
+	}
+
+	urlStr, err := documentURL(*c.rootURL, escapedPath, url.Values{})
+	if err != nil {
+		return err
+	}
+
+	job, err := c.request("PUT", urlStr, bytes.NewReader(body))
+	defer job.Close()
+	if err != nil {
+		return err
+	}
+
+	return expectedReturnCodes(job, 200)
+}
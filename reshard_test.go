@@ -0,0 +1,80 @@
+package cloudant
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestReshardJob_Unmarshal(t *testing.T) {
+	data := []byte(`{
+		"history": [
+			{"detail": null, "timestamp": "2019-03-28T15:28:02Z", "type": "new"},
+			{"detail": "Shard splitting disabled", "timestamp": "2019-03-28T15:28:02Z", "type": "stopped"}
+		],
+		"id": "001-171d1211418996ff47bd610b1d1257fc4ca2628868def4a05e63e8f8fe50694a",
+		"job_state": "completed",
+		"node": "node1@127.0.0.1",
+		"source": "shards/00000000-1fffffff/d1.1553786862",
+		"split_state": "completed",
+		"start_time": "2019-03-28T15:28:02Z",
+		"state_info": {},
+		"target": [
+			"shards/00000000-0fffffff/d1.1553786862",
+			"shards/10000000-1fffffff/d1.1553786862"
+		],
+		"type": "split",
+		"update_time": "2019-03-28T15:28:08Z"
+	}`)
+
+	job := &ReshardJob{}
+	if err := json.Unmarshal(data, job); err != nil {
+		t.Fatal(err)
+	}
+	if job.JobState != ReshardCompleted || len(job.Target) != 2 {
+		t.Errorf("unexpected job %+v", job)
+	}
+	if len(job.History) != 2 || job.History[1].Detail != "Shard splitting disabled" {
+		t.Errorf("unexpected history %+v", job.History)
+	}
+}
+
+func TestClient_ReshardJobEscaped(t *testing.T) {
+	var mutex sync.Mutex
+	requestURIs := []string{}
+	client, server := makeMockClient(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requestURIs = append(requestURIs, r.Method+" "+r.RequestURI)
+		mutex.Unlock()
+		w.Write([]byte(`{"ok":true}`))
+	})
+	defer server.Close()
+
+	jobID := "001-a/b?c"
+	if _, err := client.ReshardJob(jobID); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if err := client.SetReshardJobState(jobID, ReshardStopped, ""); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if err := client.DeleteReshardJob(jobID); err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	expected := []string{
+		"GET /_reshard/jobs/001-a%2Fb%3Fc",
+		"PUT /_reshard/jobs/001-a%2Fb%3Fc/state",
+		"DELETE /_reshard/jobs/001-a%2Fb%3Fc",
+	}
+	if len(requestURIs) != len(expected) {
+		t.Fatalf("Expected %v, found %v", expected, requestURIs)
+	}
+	for i := range expected {
+		if requestURIs[i] != expected[i] {
+			t.Errorf("Expected %s, found %s", expected[i], requestURIs[i])
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package cloudant
+
+import (
+	"encoding/json"
+	"net/url"
+	"time"
+)
+
+// ReplicationState is the state of a replication as reported by the scheduler
+type ReplicationState string
+
+// Constants defining the possible replication states
+const (
+	ReplicationInitializing ReplicationState = "initializing"
+	ReplicationError        ReplicationState = "error"
+	ReplicationPending      ReplicationState = "pending"
+	ReplicationRunning      ReplicationState = "running"
+	ReplicationCrashing     ReplicationState = "crashing"
+	ReplicationCompleted    ReplicationState = "completed"
+	ReplicationFailed       ReplicationState = "failed"
+)
+
+// SequenceID is an update sequence, which is an opaque string on Cloudant and
+// CouchDB2.X and a number on CouchDB1.6.
+type SequenceID string
+
+// UnmarshalJSON accepts both string and numeric sequences.
+func (s *SequenceID) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var str string
+		if err := json.Unmarshal(data, &str); err != nil {
+			return err
+		}
+		*s = SequenceID(str)
+		return nil
+	}
+	if string(data) == "null" {
+		*s = ""
+		return nil
+	}
+	*s = SequenceID(data)
+	return nil
+}
+
+// ReplicationInfo holds the statistics of a replication
+type ReplicationInfo struct {
+	RevisionsChecked      int64      `json:"revisions_checked"`
+	MissingRevisionsFound int64      `json:"missing_revisions_found"`
+	DocsRead              int64      `json:"docs_read"`
+	DocsWritten           int64      `json:"docs_written"`
+	ChangesPending        int64      `json:"changes_pending"`
+	DocWriteFailures      int64      `json:"doc_write_failures"`
+	CheckpointedSourceSeq SequenceID `json:"checkpointed_source_seq"`
+	SourceSeq             SequenceID `json:"source_seq"`
+	ThroughSeq            SequenceID `json:"through_seq"`
+	Error                 string     `json:"error,omitempty"`
+}
+
+// SchedulerJobEvent is an entry in the history of a replication job
+type SchedulerJobEvent struct {
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+// SchedulerJob is a replication job run by the scheduler
+type SchedulerJob struct {
+	ID        string              `json:"id"`
+	Database  string              `json:"database"`
+	DocID     string              `json:"doc_id"`
+	Node      string              `json:"node"`
+	PID       string              `json:"pid"`
+	Source    string              `json:"source"`
+	Target    string              `json:"target"`
+	User      string              `json:"user"`
+	StartTime time.Time           `json:"start_time"`
+	Info      *ReplicationInfo    `json:"info"`
+	History   []SchedulerJobEvent `json:"history"`
+}
+
+// SchedulerJobs is the response from the /_scheduler/jobs endpoint
+type SchedulerJobs struct {
+	Jobs      []SchedulerJob `json:"jobs"`
+	Offset    int            `json:"offset"`
+	TotalRows int            `json:"total_rows"`
+}
+
+// SchedulerDoc is the scheduler's view of a replication document
+type SchedulerDoc struct {
+	ID          string           `json:"id"`
+	Database    string           `json:"database"`
+	DocID       string           `json:"doc_id"`
+	Node        string           `json:"node"`
+	Source      string           `json:"source"`
+	Target      string           `json:"target"`
+	State       ReplicationState `json:"state"`
+	ErrorCount  int              `json:"error_count"`
+	StartTime   time.Time        `json:"start_time"`
+	LastUpdated time.Time        `json:"last_updated"`
+	Info        *ReplicationInfo `json:"info"`
+}
+
+// SchedulerDocs is the response from the /_scheduler/docs endpoint
+type SchedulerDocs struct {
+	Docs      []SchedulerDoc `json:"docs"`
+	Offset    int            `json:"offset"`
+	TotalRows int            `json:"total_rows"`
+}
+
+// SchedulerJobs returns the replication jobs currently run by the scheduler.
+// See: https://docs.couchdb.org/en/stable/api/server/common.html#scheduler-jobs
+func (c *CouchClient) SchedulerJobs(args *schedulerQuery) (*SchedulerJobs, error) {
+	params, err := args.GetQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := &SchedulerJobs{}
+	err = c.getJSON("/_scheduler/jobs", params, jobs)
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// SchedulerDocs returns the state of all replication documents, including
+// completed and failed ones.
+// See: https://docs.couchdb.org/en/stable/api/server/common.html#scheduler-docs
+func (c *CouchClient) SchedulerDocs(args *schedulerQuery) (*SchedulerDocs, error) {
+	params, err := args.GetQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	docs := &SchedulerDocs{}
+	err = c.getJSON("/_scheduler/docs", params, docs)
+	if err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// SchedulerDoc returns the state of a single replication document in the
+// given replicator database (usually "_replicator").
+func (c *CouchClient) SchedulerDoc(replicatorDB, documentID string) (*SchedulerDoc, error) {
+	escapedPath := "_scheduler/docs/" + escapePathSegment(replicatorDB) + "/" + documentPath(documentID)
+	urlStr, err := documentURL(*c.rootURL, escapedPath, url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &SchedulerDoc{}
+	err = c.getURLJSON(urlStr, doc)
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
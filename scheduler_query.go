@@ -0,0 +1,68 @@
+package cloudant
+
+// QueryBuilder implementation for the SchedulerJobs() and SchedulerDocs() API calls.
+//
+// Example:
+// 	query := cloudant.NewSchedulerQuery().
+//     Limit(10).
+//     Build()
+//
+//	jobs, err := client.SchedulerJobs(query)
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// SchedulerQueryBuilder defines the available parameter-setting functions.
+type SchedulerQueryBuilder interface {
+	Limit(int) SchedulerQueryBuilder
+	Skip(int) SchedulerQueryBuilder
+	Build() *schedulerQuery
+}
+
+type schedulerQueryBuilder struct {
+	limit int
+	skip  int
+}
+
+// schedulerQuery holds the implemented API call parameters.
+type schedulerQuery struct {
+	Limit int
+	Skip  int
+}
+
+// NewSchedulerQuery is the entry point.
+func NewSchedulerQuery() SchedulerQueryBuilder {
+	return &schedulerQueryBuilder{}
+}
+
+func (s *schedulerQueryBuilder) Limit(lim int) SchedulerQueryBuilder {
+	s.limit = lim
+	return s
+}
+
+func (s *schedulerQueryBuilder) Skip(skip int) SchedulerQueryBuilder {
+	s.skip = skip
+	return s
+}
+
+// GetQuery implements the QueryBuilder interface. It returns an
+// url.Values map with the non-default values set.
+func (sq *schedulerQuery) GetQuery() (url.Values, error) {
+	vals := url.Values{}
+	if sq.Limit > 0 {
+		vals.Set("limit", strconv.Itoa(sq.Limit))
+	}
+	if sq.Skip > 0 {
+		vals.Set("skip", strconv.Itoa(sq.Skip))
+	}
+	return vals, nil
+}
+
+func (s *schedulerQueryBuilder) Build() *schedulerQuery {
+	return &schedulerQuery{
+		Limit: s.limit,
+		Skip:  s.skip,
+	}
+}
@@ -0,0 +1,31 @@
+package cloudant
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSchedulerQuery_Args(t *testing.T) {
+	// Limit int
+	// Skip  int
+
+	expectedQueryStrings := []string{
+		"limit=10",
+		"skip=20",
+	}
+
+	query := NewSchedulerQuery().
+		Limit(10).
+		Skip(20).
+		Build()
+
+	values, _ := query.GetQuery()
+	queryString := values.Encode()
+
+	for _, str := range expectedQueryStrings {
+		if !strings.Contains(queryString, str) {
+			t.Errorf("parameter encoding not found '%s' in '%s'", str, queryString)
+			return
+		}
+	}
+}
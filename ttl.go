@@ -0,0 +1,283 @@
+package cloudant
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultExpiryField is the document field read by a Sweeper unless another
+// is configured. It holds the expiry time as seconds since the Unix epoch.
+const DefaultExpiryField = "expires_at"
+
+// TTLDesignDoc is the design document holding the indexes created by
+// Sweeper.EnsureIndex
+const TTLDesignDoc = "_design/cloudant-ttl"
+
+// defaultSweepInterval is the time between sweeps unless another is configured
+const defaultSweepInterval = time.Minute
+
+// ExpiresIn returns the value of an expiry field for a document that should
+// be removed after ttl.
+//
+// Example:
+//
+//	session := map[string]interface{}{
+//		"user":                      "jane",
+//		cloudant.DefaultExpiryField: cloudant.ExpiresIn(24 * time.Hour),
+//	}
+func ExpiresIn(ttl time.Duration) int64 {
+	return time.Now().Add(ttl).Unix()
+}
+
+// SweeperMetrics are counters describing the work done by a Sweeper
+type SweeperMetrics struct {
+	Sweeps       int64         // completed sweeps, successful or not
+	Expired      int64         // expired documents found
+	Removed      int64         // documents deleted or purged
+	Failed       int64         // documents that could not be removed
+	LastSweep    time.Time     // start of the last sweep
+	LastDuration time.Duration // duration of the last sweep
+	LastError    error         // error of the last sweep, if any
+}
+
+// Sweeper periodically finds documents whose expiry field is in the past and
+// deletes them through a bulk Uploader, or purges them if Purge is set. Purging
+// removes every leaf revision of a document, conflicts and deleted leaves
+// included. Documents without a numeric expiry field never expire.
+type Sweeper struct {
+	db           *Database
+	Field        string        // expiry field, seconds since the Unix epoch
+	Interval     time.Duration // time between sweeps started by Start, a minute if not positive
+	BatchSize    int           // documents fetched and removed per request, 100 if not positive
+	MaxPerSecond int           // maximum documents removed per second, 0 for no limit
+	Purge        bool          // purge instead of delete, leaving no tombstones
+
+	mutex   sync.Mutex
+	metrics SweeperMetrics
+	stop    chan struct{}
+	stopped chan struct{}
+	now     func() time.Time
+}
+
+// NewSweeper creates a Sweeper on database using DefaultExpiryField.
+func NewSweeper(database *Database) *Sweeper {
+	return &Sweeper{
+		db:        database,
+		Field:     DefaultExpiryField,
+		Interval:  defaultSweepInterval,
+		BatchSize: 100,
+		now:       time.Now,
+	}
+}
+
+// EnsureIndex creates the json index on the expiry field used to find expired
+// documents, if it doesn't exist already.
+func (s *Sweeper) EnsureIndex() error {
+	_, err := s.db.Index(NewCreateIndex().
+		Fields([]string{s.Field}).
+		DDoc(TTLDesignDoc).
+		Name(s.indexName()).
+		Type(IndexTypeJSON).
+		Build())
+	return err
+}
+
+// Start runs a sweep immediately and then every Interval in the background,
+// until Stop is called. Errors are reported in Metrics().LastError.
+func (s *Sweeper) Start() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.stop != nil {
+		return
+	}
+	s.stop = make(chan struct{})
+	s.stopped = make(chan struct{})
+
+	interval := s.Interval
+	if interval <= 0 {
+		interval = defaultSweepInterval
+	}
+
+	go func(stop, stopped chan struct{}) {
+		defer close(stopped)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := s.sweep(stop); err != nil {
+				LogFunc("ttl sweep of %s failed, %s", s.db.Name, err)
+			}
+
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}(s.stop, s.stopped)
+}
+
+// Stop terminates the background sweeps, waiting for a sweep in progress to
+// finish its current batch.
+func (s *Sweeper) Stop() {
+	s.mutex.Lock()
+	stop, stopped := s.stop, s.stopped
+	s.stop, s.stopped = nil, nil
+	s.mutex.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-stopped
+	}
+}
+
+// Sweep removes all currently expired documents and returns how many were
+// removed.
+func (s *Sweeper) Sweep() (int, error) {
+	return s.sweep(nil)
+}
+
+// Metrics returns a snapshot of the sweeper's counters.
+func (s *Sweeper) Metrics() SweeperMetrics {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.metrics
+}
+
+func (s *Sweeper) sweep(stop chan struct{}) (int, error) {
+	start := s.now()
+	removed, failed, expired := 0, 0, 0
+
+	batchSize := s.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	err := func() error {
+		bookmark := ""
+		for {
+			batchStart := time.Now()
+
+			found, err := s.db.Find(s.expiredQuery(start, bookmark, batchSize))
+			if err != nil {
+				return err
+			}
+			expired += len(found.Docs)
+
+			// documents not removed, including those whose leaf revisions
+			// could not be read when purging, are failures
+			ok, err := s.remove(found.Docs)
+			removed += ok
+			failed += len(found.Docs) - ok
+			if err != nil {
+				return err
+			}
+
+			if len(found.Docs) < batchSize || found.Bookmark == "" {
+				return nil
+			}
+			bookmark = found.Bookmark
+
+			// rate limit: a batch of n documents takes at least n/MaxPerSecond
+			var wait time.Duration
+			if s.MaxPerSecond > 0 {
+				wait = time.Duration(len(found.Docs))*time.Second/time.Duration(s.MaxPerSecond) - time.Since(batchStart)
+			}
+			select {
+			case <-stop:
+				return nil
+			case <-time.After(wait):
+			}
+		}
+	}()
+
+	s.mutex.Lock()
+	s.metrics.Sweeps++
+	s.metrics.Expired += int64(expired)
+	s.metrics.Removed += int64(removed)
+	s.metrics.Failed += int64(failed)
+	s.metrics.LastSweep = start
+	s.metrics.LastDuration = s.now().Sub(start)
+	s.metrics.LastError = err
+	s.mutex.Unlock()
+
+	return removed, err
+}
+
+// expiredQuery finds the IDs and revisions of up to limit documents expired
+// at now. The $type operator keeps null and boolean values, which collate
+// before numbers, out of the results.
+func (s *Sweeper) expiredQuery(now time.Time, bookmark string, limit int) *find {
+	return NewFind().
+		SetSelector(s.Field, map[string]interface{}{"$type": "number", "$lt": now.Unix()}).
+		Fields([]string{"_id", "_rev"}).
+		Limit(limit).
+		Bookmark(bookmark).
+		UseIndex(TTLDesignDoc + "/" + s.indexName()).
+		Build()
+}
+
+// remove deletes or purges documents returned by an expiredQuery, returning
+// how many were removed.
+func (s *Sweeper) remove(docs []interface{}) (int, error) {
+	if len(docs) == 0 {
+		return 0, nil
+	}
+
+	if s.Purge {
+		// the query only returns the winning revision, which would leave any
+		// conflicts behind as the new winner
+		revs := map[string][]string{}
+		for _, doc := range docs {
+			meta := GetDocumentMeta(doc)
+			leaves, err := s.db.LeafRevisions(meta.ID)
+			if err != nil {
+				LogFunc("failed to get leaf revisions of %s, %s", meta.ID, err)
+				continue
+			}
+			if len(leaves) > 0 {
+				revs[meta.ID] = leaves
+			}
+		}
+		if len(revs) == 0 {
+			return 0, nil
+		}
+
+		result, err := s.db.Purge(revs)
+		if err != nil {
+			return 0, err
+		}
+		removed := 0
+		for _, purged := range result.Purged {
+			if len(purged) > 0 {
+				removed++
+			}
+		}
+		return removed, nil
+	}
+
+	uploader := s.db.Bulk(len(docs), 0, 0)
+	defer uploader.Stop()
+
+	jobs := make([]*BulkJob, len(docs))
+	for i, doc := range docs {
+		meta := GetDocumentMeta(doc)
+		jobs[i] = uploader.Upload(map[string]interface{}{"_id": meta.ID, "_rev": meta.Rev, "_deleted": true})
+	}
+	uploader.Flush()
+
+	removed := 0
+	for _, job := range jobs {
+		job.Wait()
+		if job.Error == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+func (s *Sweeper) indexName() string {
+	return "ttl-" + s.Field
+}
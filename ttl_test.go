@@ -0,0 +1,212 @@
+package cloudant
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSweeper_ExpiredQuery(t *testing.T) {
+	sweeper := NewSweeper(&Database{})
+	sweeper.Field = "ttl"
+
+	query, _ := json.Marshal(sweeper.expiredQuery(time.Unix(1500000000, 0), "abc", 10))
+
+	expected := `{"selector":{"ttl":{"$lt":1500000000,"$type":"number"}},"limit":10,"fields":["_id","_rev"],"bookmark":"abc","use_index":"_design/cloudant-ttl/ttl-ttl"}`
+	if string(query) != expected {
+		t.Errorf("Expected %s, found %s", expected, query)
+	}
+}
+
+func TestSweeper_PurgeLeaves(t *testing.T) {
+	var mutex sync.Mutex
+	purged := ""
+	client, server := makeMockClient(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/_find"):
+			w.Write([]byte(`{"docs":[{"_id":"doc","_rev":"2-b"}],"bookmark":""}`))
+		case r.URL.Query().Get("open_revs") == "all":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[{"ok":{"_id":"doc","_rev":"2-b"}},{"ok":{"_id":"doc","_rev":"2-a"}}]`))
+		case strings.HasSuffix(r.URL.Path, "/_purge"):
+			data, _ := ioutil.ReadAll(r.Body)
+			mutex.Lock()
+			purged = string(data)
+			mutex.Unlock()
+			w.WriteHeader(201)
+			w.Write([]byte(`{"purge_seq":null,"purged":{"doc":["2-b","2-a"]}}`))
+		default:
+			w.WriteHeader(404)
+		}
+	})
+	defer server.Close()
+
+	database, err := client.Get("db")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	sweeper := NewSweeper(database)
+	sweeper.Purge = true
+	removed, err := sweeper.Sweep()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if err != nil || removed != 1 || purged != `{"doc":["2-b","2-a"]}` {
+		t.Errorf("expected every leaf to be purged, found %s (%d, %v)", purged, removed, err)
+	}
+}
+
+func TestSweeper_ZeroBatchSize(t *testing.T) {
+	var mutex sync.Mutex
+	queries := []string{}
+	client, server := makeMockClient(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/_find") {
+			data, _ := ioutil.ReadAll(r.Body)
+			mutex.Lock()
+			queries = append(queries, string(data))
+			mutex.Unlock()
+			w.Write([]byte(`{"docs":[{"_id":"doc","_rev":"1-a"}],"bookmark":"next"}`))
+			return
+		}
+		w.WriteHeader(201)
+		w.Write([]byte(`[{"ok":true,"id":"doc","rev":"2-b"}]`))
+	})
+	defer server.Close()
+
+	database, err := client.Get("db")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	sweeper := NewSweeper(database)
+	sweeper.BatchSize = 0
+	removed, err := sweeper.Sweep()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if err != nil || removed != 1 || len(queries) != 1 || !strings.Contains(queries[0], `"limit":100`) {
+		t.Errorf("expected a single query of 100 documents, found %v (%d, %v)", queries, removed, err)
+	}
+}
+
+func TestSweeper_PurgeLeavesError(t *testing.T) {
+	client, server := makeMockClient(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/_find"):
+			w.Write([]byte(`{"docs":[{"_id":"doc","_rev":"2-b"},{"_id":"other","_rev":"1-a"}],"bookmark":""}`))
+		case strings.HasSuffix(r.URL.Path, "/other"):
+			w.WriteHeader(500)
+			w.Write([]byte(`{"error":"internal_server_error","reason":"boom"}`))
+		case r.URL.Query().Get("open_revs") == "all":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[{"ok":{"_id":"doc","_rev":"2-b"}}]`))
+		case strings.HasSuffix(r.URL.Path, "/_purge"):
+			w.WriteHeader(201)
+			w.Write([]byte(`{"purge_seq":null,"purged":{"doc":["2-b"]}}`))
+		default:
+			w.WriteHeader(404)
+		}
+	})
+	defer server.Close()
+
+	database, err := client.Get("db")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	sweeper := NewSweeper(database)
+	sweeper.Purge = true
+	removed, err := sweeper.Sweep()
+
+	metrics := sweeper.Metrics()
+	if err != nil || removed != 1 || metrics.Removed != 1 || metrics.Failed != 1 {
+		t.Errorf("expected the failed lookup to be counted, found %+v (%d, %v)", metrics, removed, err)
+	}
+}
+
+func TestSweeper_ZeroInterval(t *testing.T) {
+	client, server := makeMockClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"docs":[],"bookmark":""}`))
+	})
+	defer server.Close()
+
+	database, err := client.Get("db")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	sweeper := NewSweeper(database)
+	sweeper.Interval = 0
+	sweeper.Start()
+	sweeper.Stop()
+
+	if metrics := sweeper.Metrics(); metrics.Sweeps != 1 || metrics.LastError != nil {
+		t.Errorf("unexpected metrics %+v", metrics)
+	}
+}
+
+func TestDatabase_Sweeper(t *testing.T) {
+	database, err := makeDatabase()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer func() {
+		fmt.Printf("Deleting database %s", database.Name)
+		database.client.Delete(database.Name)
+	}()
+
+	sweeper := NewSweeper(database)
+	sweeper.BatchSize = 2
+	if err = sweeper.EnsureIndex(); err != nil {
+		t.Fatalf("failed to create index: %s", err)
+	}
+
+	docs := map[string]interface{}{
+		"doc-expired-1": time.Now().Add(-time.Hour).Unix(),
+		"doc-expired-2": time.Now().Add(-time.Minute).Unix(),
+		"doc-expired-3": time.Now().Add(-time.Second).Unix(),
+		"doc-live":      ExpiresIn(time.Hour),
+		"doc-no-expiry": nil,
+	}
+	for id, expiry := range docs {
+		_, err = database.Set(map[string]interface{}{"_id": id, DefaultExpiryField: expiry})
+		if err != nil {
+			t.Fatalf("failed to create document: %s", err)
+		}
+	}
+
+	// Note: lame attempt to close inconsistency window
+	time.Sleep(500 * time.Millisecond)
+
+	removed, err := sweeper.Sweep()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if removed != 3 {
+		t.Errorf("Expected 3 documents removed, found %d", removed)
+	}
+
+	// Note: lame attempt to close inconsistency window
+	time.Sleep(500 * time.Millisecond)
+
+	for id := range docs {
+		err = database.Get(id, &getQuery{}, &struct{}{})
+		expired := id != "doc-live" && id != "doc-no-expiry"
+		if dberr, ok := err.(*CouchError); expired && (!ok || dberr.StatusCode != 404) {
+			t.Errorf("expected %s to be deleted, got %v", id, err)
+		} else if !expired && err != nil {
+			t.Errorf("expected %s to be kept, got %v", id, err)
+		}
+	}
+
+	metrics := sweeper.Metrics()
+	if metrics.Sweeps != 1 || metrics.Expired != 3 || metrics.Removed != 3 || metrics.Failed != 0 {
+		t.Errorf("unexpected metrics %+v", metrics)
+	}
+}
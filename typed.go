@@ -0,0 +1,188 @@
+package cloudant
+
+import (
+	"encoding/json"
+)
+
+// TypedDatabase wraps a Database to read and write documents of type T
+// directly, decoding results without intermediate maps.
+//
+// Example:
+//
+//	type Doc struct {
+//		ID  string `json:"_id"`
+//		Rev string `json:"_rev,omitempty"`
+//		Foo string `json:"foo"`
+//	}
+//
+//	docs := cloudant.NewTypedDatabase[Doc](db)
+//	doc, err := docs.Get("my_doc", cloudant.NewGetQuery().Build())
+type TypedDatabase[T any] struct {
+	db *Database
+}
+
+// TypedRow is a row returned by TypedDatabase.All. Doc is nil unless the
+// IncludeDocs() query option was set, or if it could not be decoded as T, in
+// which case Err is set.
+type TypedRow[T any] struct {
+	ID  string
+	Rev string
+	Doc *T
+	Err error
+}
+
+// TypedChange is a change returned by TypedDatabase.Changes. Doc is nil unless
+// the IncludeDocs() query option was set, or if it could not be decoded as T,
+// in which case Err is set.
+type TypedChange[T any] struct {
+	ID      string
+	Rev     string
+	Seq     string
+	Deleted bool
+	Doc     *T
+	Err     error
+}
+
+// TypedFindResponse is the response from a TypedDatabase.Find query
+type TypedFindResponse[T any] struct {
+	Docs           []T             `json:"docs"`
+	Bookmark       string          `json:"bookmark,omitempty"`
+	ExecutionStats executionStatus `json:"execution_stats,omitempty"`
+}
+
+// typedAllRow is a row of _all_docs keeping the document undecoded
+type typedAllRow struct {
+	ID    string          `json:"id"`
+	Value AllRowValue     `json:"value"`
+	Doc   json.RawMessage `json:"doc"`
+}
+
+// typedChangeRow is a row of _changes keeping the document undecoded
+type typedChangeRow struct {
+	ID      string             `json:"id"`
+	Seq     SequenceID         `json:"seq"`
+	Changes []ChangeRowChanges `json:"changes"`
+	Deleted bool               `json:"deleted"`
+	Doc     json.RawMessage    `json:"doc"`
+}
+
+// NewTypedDatabase returns a typed view of database for documents of type T.
+func NewTypedDatabase[T any](database *Database) *TypedDatabase[T] {
+	return &TypedDatabase[T]{db: database}
+}
+
+// Database returns the underlying untyped database.
+func (t *TypedDatabase[T]) Database() *Database {
+	return t.db
+}
+
+// Get a document from the database.
+func (t *TypedDatabase[T]) Get(documentID string, args *getQuery) (*T, error) {
+	doc := new(T)
+	err := t.db.Get(documentID, args, doc)
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// Put creates or updates the document with the given ID. Its '_id' and
+// '_rev' fields are updated to the values assigned by the server.
+func (t *TypedDatabase[T]) Put(documentID string, document *T, args *writeQuery) (*WriteResult, error) {
+	return t.db.Put(documentID, document, args)
+}
+
+// Update performs a read-modify-write of a document with conflict retry, as
+// Database.Update, returning the saved document.
+func (t *TypedDatabase[T]) Update(documentID string, mutate func(doc *T) error) (*T, error) {
+	doc := new(T)
+	_, err := t.db.Update(documentID, doc, func(interface{}) error { return mutate(doc) })
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// Find performs a document query, decoding the matching documents as T.
+func (t *TypedDatabase[T]) Find(findArgs *find) (*TypedFindResponse[T], error) {
+	findResp := &TypedFindResponse[T]{}
+	err := t.db.find(findArgs, findResp)
+	if err != nil {
+		return nil, err
+	}
+	return findResp, nil
+}
+
+// All returns a channel in which the rows of _all_docs can be received.
+func (t *TypedDatabase[T]) All(args *allDocsQuery) (<-chan *TypedRow[T], error) {
+	lines, err := t.db.allDocsLines("/_all_docs", args)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(chan *TypedRow[T], 1000)
+
+	go func() {
+		defer close(results)
+		for line := range lines {
+			row := &typedAllRow{}
+			if err := json.Unmarshal(line, row); err != nil {
+				LogFunc("failed to decode row of %s, %s", t.db.Name, err)
+				continue
+			}
+
+			result := &TypedRow[T]{ID: row.ID, Rev: row.Value.Rev}
+			result.Doc, result.Err = decodeTyped[T](row.Doc)
+			results <- result
+		}
+	}()
+
+	return results, nil
+}
+
+// Changes returns a channel in which the rows of _changes can be received.
+func (t *TypedDatabase[T]) Changes(args *changesQuery) (<-chan *TypedChange[T], error) {
+	lines, err := t.db.changesLines(args)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(chan *TypedChange[T], 1000)
+
+	go func() {
+		defer close(changes)
+		for line := range lines {
+			row := &typedChangeRow{}
+			if err := json.Unmarshal(line, row); err != nil {
+				LogFunc("failed to decode change of %s, %s", t.db.Name, err)
+				continue
+			}
+			if len(row.Changes) != 1 {
+				continue
+			}
+
+			change := &TypedChange[T]{
+				ID:      row.ID,
+				Rev:     row.Changes[0].Rev,
+				Seq:     string(row.Seq),
+				Deleted: row.Deleted,
+			}
+			change.Doc, change.Err = decodeTyped[T](row.Doc)
+			changes <- change
+		}
+	}()
+
+	return changes, nil
+}
+
+// decodeTyped decodes a raw document, returning nil if there is none.
+func decodeTyped[T any](data json.RawMessage) (*T, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	doc := new(T)
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
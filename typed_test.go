@@ -0,0 +1,124 @@
+package cloudant
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTyped_Decode(t *testing.T) {
+	doc, err := decodeTyped[cloudantDocument]([]byte(`{"_id":"doc","foo":"mydata","bar":57}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.ID != "doc" || doc.Foo != "mydata" || doc.Bar != 57 {
+		t.Errorf("unexpected document %+v", doc)
+	}
+
+	for _, data := range []string{"", "null"} {
+		doc, err = decodeTyped[cloudantDocument]([]byte(data))
+		if err != nil || doc != nil {
+			t.Errorf("expected no document for %q, found %+v (%v)", data, doc, err)
+		}
+	}
+}
+
+func TestTypedDatabase_AllDecodeError(t *testing.T) {
+	client, server := makeMockClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"total_rows":2,"offset":0,"rows":[` + "\n" +
+			`{"id":"doc-1","key":"doc-1","value":{"rev":"1-a"},"doc":{"_id":"doc-1","bar":57}},` + "\n" +
+			`{"id":"doc-2","key":"doc-2","value":{"rev":"1-b"},"doc":{"_id":"doc-2","bar":"57"}}` + "\n" +
+			`]}` + "\n"))
+	})
+	defer server.Close()
+
+	database, err := client.Get("db")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	rows, err := NewTypedDatabase[cloudantDocument](database).All(NewAllDocsQuery().IncludeDocs().Build())
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	results := []*TypedRow[cloudantDocument]{}
+	for row := range rows {
+		results = append(results, row)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 rows, found %d", len(results))
+	}
+	if results[0].Err != nil || results[0].Doc.Bar != 57 {
+		t.Errorf("unexpected row %+v", results[0])
+	}
+	if results[1].ID != "doc-2" || results[1].Err == nil || results[1].Doc != nil {
+		t.Errorf("expected a decoding error, found %+v", results[1])
+	}
+}
+
+func TestTypedDatabase(t *testing.T) {
+	database, err := makeDatabase()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer func() {
+		fmt.Printf("Deleting database %s", database.Name)
+		database.client.Delete(database.Name)
+	}()
+
+	docs := NewTypedDatabase[cloudantDocument](database)
+
+	_, err = docs.Put("doc-typed", &cloudantDocument{Foo: "mydata", Bar: 57}, NewWriteQuery().Build())
+	if err != nil {
+		t.Fatalf("failed to create document: %s", err)
+	}
+
+	// Note: lame attempt to close inconsistency window
+	time.Sleep(500 * time.Millisecond)
+
+	doc, err := docs.Get("doc-typed", NewGetQuery().Build())
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if doc.ID != "doc-typed" || doc.Bar != 57 {
+		t.Errorf("unexpected document %+v", doc)
+	}
+
+	doc, err = docs.Update("doc-typed", func(doc *cloudantDocument) error {
+		doc.Bar++
+		return nil
+	})
+	if err != nil || doc.Bar != 58 {
+		t.Fatalf("unexpected update %+v (%v)", doc, err)
+	}
+
+	rows, err := docs.All(NewAllDocsQuery().IncludeDocs().Build())
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	for row := range rows {
+		if row.ID != "doc-typed" || row.Doc == nil || row.Doc.Bar != 58 {
+			t.Errorf("unexpected row %+v", row)
+		}
+	}
+
+	changes, err := docs.Changes(NewChangesQuery().IncludeDocs().Build())
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	for change := range changes {
+		if change.ID != "doc-typed" || change.Seq == "" || change.Doc == nil || change.Doc.Foo != "mydata" {
+			t.Errorf("unexpected change %+v", change)
+		}
+	}
+
+	found, err := docs.Find(NewFind().SetSelector("bar", 58).Build())
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if len(found.Docs) != 1 || found.Docs[0].ID != "doc-typed" {
+		t.Errorf("unexpected find response %+v", found)
+	}
+}
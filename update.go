@@ -0,0 +1,112 @@
+package cloudant
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+// UpdateFunc mutates a document in place. doc is the target passed to Update
+// or Upsert, freshly decoded from the latest revision. Returning an error
+// aborts the update and the error is returned to the caller.
+type UpdateFunc func(doc interface{}) error
+
+// UpdatePolicy controls how Update and Upsert retry on conflicts.
+type UpdatePolicy struct {
+	MaxRetries int           // retries after a conflict before giving up
+	Delay      time.Duration // delay before the first retry, doubled after each one
+}
+
+// DefaultUpdatePolicy is used by Update and Upsert if the database has no
+// UpdatePolicy of its own.
+var DefaultUpdatePolicy = UpdatePolicy{MaxRetries: 5, Delay: 50 * time.Millisecond}
+
+// Update performs a read-modify-write of a document: it gets the current
+// revision into target (a pointer), applies mutate and saves the result. On a
+// conflict the document is read again and mutate re-applied, as allowed by the
+// database's UpdatePolicy.
+func (d *Database) Update(documentID string, target interface{}, mutate UpdateFunc) (*DocumentMeta, error) {
+	return d.update(documentID, target, mutate, false)
+}
+
+// Upsert is like Update, but if the document doesn't exist mutate is applied
+// to the zero value of target and the document is created.
+func (d *Database) Upsert(documentID string, target interface{}, mutate UpdateFunc) (*DocumentMeta, error) {
+	return d.update(documentID, target, mutate, true)
+}
+
+func (d *Database) update(documentID string, target interface{}, mutate UpdateFunc, create bool) (*DocumentMeta, error) {
+	policy := DefaultUpdatePolicy
+	if d.UpdatePolicy != nil {
+		policy = *d.UpdatePolicy
+	}
+
+	delay := policy.Delay
+	for retry := 0; ; retry++ {
+		meta, err := d.tryUpdate(documentID, target, mutate, create)
+		if dbErr, ok := err.(*CouchError); ok && dbErr.StatusCode == 409 && retry < policy.MaxRetries {
+			time.Sleep(delay)
+			delay *= 2
+			continue
+		}
+		return meta, err
+	}
+}
+
+func (d *Database) tryUpdate(documentID string, target interface{}, mutate UpdateFunc, create bool) (*DocumentMeta, error) {
+	rev := ""
+	var kept *encryptedPaths
+
+	doc, err := d.getRaw(documentID, &getQuery{})
+	if dbErr, ok := err.(*CouchError); ok && dbErr.StatusCode == 404 && create {
+		// the zero value may have an empty '_id' field, which would be sent
+		if err = resetTarget(target); err == nil {
+			SetDocumentMeta(target, &DocumentMeta{ID: documentID})
+		}
+	} else if err == nil {
+		if err = resetTarget(target); err != nil {
+			return nil, err
+		}
+		meta := &openRevsDoc{}
+		if err = json.Unmarshal(doc, meta); err != nil {
+			return nil, err
+		}
+		rev = meta.Rev
+		if err = d.decodeJSON(doc, target); err == nil {
+			// untyped targets, e.g. for Patch, have no tags to encrypt by
+			kept, err = storedEncryptedPaths(doc)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = mutate(target); err != nil {
+		return nil, err
+	}
+
+	result, err := d.rewrite(documentID, target, NewWriteQuery().Rev(rev).Build(), kept)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result.DocumentMeta, nil
+}
+
+// resetTarget sets the value target points to back to its zero value, so
+// that fields missing from a newer revision don't linger between retries.
+// Maps are reset to an empty map rather than nil, so they can be written to.
+func resetTarget(target interface{}) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return &json.InvalidUnmarshalError{Type: reflect.TypeOf(target)}
+	}
+
+	elem := value.Elem()
+	if elem.Kind() == reflect.Map {
+		elem.Set(reflect.MakeMap(elem.Type()))
+	} else {
+		elem.Set(reflect.Zero(elem.Type()))
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package cloudant
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestUpdate_ResetTarget(t *testing.T) {
+	doc := &cloudantDocument{ID: "doc", Foo: "stale", Bar: 1}
+	if err := resetTarget(doc); err != nil {
+		t.Fatal(err)
+	}
+	if *doc != (cloudantDocument{}) {
+		t.Errorf("expected zero value, found %+v", doc)
+	}
+
+	if err := resetTarget(cloudantDocument{}); err == nil {
+		t.Error("expected error for non-pointer target")
+	}
+}
+
+func TestDatabase_UpsertCreate(t *testing.T) {
+	var body string
+	client, server := makeMockClient(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			w.WriteHeader(404)
+			w.Write([]byte(`{"error":"not_found","reason":"missing"}`))
+		case "PUT":
+			data, _ := ioutil.ReadAll(r.Body)
+			body = string(data)
+			w.WriteHeader(201)
+			w.Write([]byte(`{"ok":true,"id":"doc-upsert","rev":"1-a"}`))
+		}
+	})
+	defer server.Close()
+
+	database, err := client.Get("db")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	doc := &cloudantDocument{}
+	_, err = database.Upsert("doc-upsert", doc, func(interface{}) error {
+		doc.Foo = "created"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to upsert document: %s", err)
+	}
+	if !strings.Contains(body, `"_id":"doc-upsert"`) || doc.ID != "doc-upsert" {
+		t.Errorf("expected document ID to be sent, found %s", body)
+	}
+}
+
+func TestDatabase_UpsertCreateMap(t *testing.T) {
+	var body string
+	client, server := makeMockClient(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			w.WriteHeader(404)
+			w.Write([]byte(`{"error":"not_found","reason":"missing"}`))
+		case "PUT":
+			data, _ := ioutil.ReadAll(r.Body)
+			body = string(data)
+			w.WriteHeader(201)
+			w.Write([]byte(`{"ok":true,"id":"doc-upsert","rev":"1-a"}`))
+		}
+	})
+	defer server.Close()
+
+	database, err := client.Get("db")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	doc := map[string]interface{}{}
+	_, err = database.Upsert("doc-upsert", &doc, func(target interface{}) error {
+		(*target.(*map[string]interface{}))["foo"] = "created"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to upsert document: %s", err)
+	}
+	if body != `{"_id":"doc-upsert","foo":"created"}` {
+		t.Errorf("unexpected document sent %s", body)
+	}
+}
+
+func TestDatabase_UpdateDecryptsOnce(t *testing.T) {
+	encryption := testEncryption()
+	keyID, key, _ := encryption.Keys.CurrentKey()
+	inner, _ := encryption.encryptValue("ssn", "123-45-6789", keyID, key, false)
+	outer, _ := encryption.encryptValue("ssn", inner, keyID, key, false)
+
+	client, server := makeMockClient(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			fmt.Fprintf(w, `{"_id":"doc","_rev":"1-a","ssn":%q}`, outer)
+		case "PUT":
+			w.WriteHeader(201)
+			w.Write([]byte(`{"ok":true,"id":"doc","rev":"2-b"}`))
+		}
+	})
+	defer server.Close()
+
+	database, err := client.Get("db")
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	database.Encryption = encryption
+
+	ssn := ""
+	_, err = database.Update("doc", &encryptedDocument{}, func(target interface{}) error {
+		ssn = target.(*encryptedDocument).SSN
+		return nil
+	})
+	if err != nil || ssn != inner {
+		t.Errorf("expected a single decryption, found %s (%v)", ssn, err)
+	}
+}
+
+func TestDatabase_Update(t *testing.T) {
+	database, err := makeDatabase()
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer func() {
+		fmt.Printf("Deleting database %s", database.Name)
+		database.client.Delete(database.Name)
+	}()
+
+	increment := func(doc interface{}) error {
+		doc.(*cloudantDocument).Bar++
+		return nil
+	}
+
+	_, err = database.Upsert("doc-update", &cloudantDocument{}, increment)
+	if err != nil {
+		t.Fatalf("failed to upsert document: %s", err)
+	}
+
+	// Note: lame attempt to close inconsistency window
+	time.Sleep(500 * time.Millisecond)
+
+	// concurrent updates conflict and are retried
+	database.UpdatePolicy = &UpdatePolicy{MaxRetries: 20, Delay: 10 * time.Millisecond}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := database.Update("doc-update", &cloudantDocument{}, increment); err != nil {
+				t.Errorf("failed to update document: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	doc := &cloudantDocument{}
+	err = database.Get("doc-update", &getQuery{}, doc)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	if doc.Bar != 5 {
+		t.Errorf("expected 5 updates, found %d", doc.Bar)
+	}
+
+	_, err = database.Update("doc-missing", &cloudantDocument{}, increment)
+	if dberr, ok := err.(*CouchError); !ok || dberr.StatusCode != 404 {
+		t.Errorf("expected 404 updating a missing document, got %v", err)
+	}
+}
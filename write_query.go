@@ -0,0 +1,98 @@
+package cloudant
+
+// QueryBuilder implementation for the Put() and Create() API calls.
+//
+// Example:
+// 	query := cloudant.NewWriteQuery().
+//     W(2).
+//     Build()
+//
+//	result, err := db.Put(docID, doc, query)
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// WriteQueryBuilder defines the available parameter-setting functions.
+type WriteQueryBuilder interface {
+	Batch() WriteQueryBuilder
+	NewEdits(bool) WriteQueryBuilder
+	Rev(string) WriteQueryBuilder
+	W(int) WriteQueryBuilder
+	Build() *writeQuery
+}
+
+type writeQueryBuilder struct {
+	batch    bool
+	newEdits *bool
+	rev      string
+	w        int
+}
+
+// writeQuery holds the implemented API call parameters.
+type writeQuery struct {
+	Batch    bool
+	NewEdits *bool
+	Rev      string
+	W        int
+}
+
+// NewWriteQuery is the entry point.
+func NewWriteQuery() WriteQueryBuilder {
+	return &writeQueryBuilder{}
+}
+
+// Batch asks the server to acknowledge the write before committing it
+// (batch=ok). The returned WriteResult will be Accepted rather than committed.
+func (w *writeQueryBuilder) Batch() WriteQueryBuilder {
+	w.batch = true
+	return w
+}
+
+func (w *writeQueryBuilder) NewEdits(newEdits bool) WriteQueryBuilder {
+	w.newEdits = &newEdits
+	return w
+}
+
+func (w *writeQueryBuilder) Rev(rev string) WriteQueryBuilder {
+	w.rev = rev
+	return w
+}
+
+// W sets the write quorum, the number of copies that must be written before
+// the write is reported as committed.
+func (w *writeQueryBuilder) W(quorum int) WriteQueryBuilder {
+	w.w = quorum
+	return w
+}
+
+// GetQuery implements the QueryBuilder interface. It returns an
+// url.Values map with the non-default values set.
+func (wq *writeQuery) GetQuery() (url.Values, error) {
+	vals := url.Values{}
+
+	if wq.Batch {
+		vals.Set("batch", "ok")
+	}
+	if wq.NewEdits != nil {
+		vals.Set("new_edits", strconv.FormatBool(*wq.NewEdits))
+	}
+	if wq.Rev != "" {
+		vals.Set("rev", wq.Rev)
+	}
+	if wq.W > 0 {
+		vals.Set("w", strconv.Itoa(wq.W))
+	}
+
+	return vals, nil
+}
+
+func (w *writeQueryBuilder) Build() *writeQuery {
+	return &writeQuery{
+		Batch:    w.batch,
+		NewEdits: w.newEdits,
+		Rev:      w.rev,
+		W:        w.w,
+	}
+}
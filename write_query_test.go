@@ -0,0 +1,42 @@
+package cloudant
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteQuery_Args(t *testing.T) {
+	// Batch    bool
+	// NewEdits *bool
+	// Rev      string
+	// W        int
+
+	expectedQueryStrings := []string{
+		"batch=ok",
+		"new_edits=false",
+		"rev=1-bf1b7e045f2843995184f78022b3d0f5",
+		"w=2",
+	}
+
+	query := NewWriteQuery().
+		Batch().
+		NewEdits(false).
+		Rev("1-bf1b7e045f2843995184f78022b3d0f5").
+		W(2).
+		Build()
+
+	values, _ := query.GetQuery()
+	queryString := values.Encode()
+
+	for _, str := range expectedQueryStrings {
+		if !strings.Contains(queryString, str) {
+			t.Errorf("parameter encoding not found '%s' in '%s'", str, queryString)
+			return
+		}
+	}
+
+	values, _ = NewWriteQuery().Build().GetQuery()
+	if len(values) != 0 {
+		t.Errorf("unexpected default parameters '%s'", values.Encode())
+	}
+}